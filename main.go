@@ -1,19 +1,33 @@
 package main
 
 import (
+	"archive/zip"
 	"bytes"
 	"embed"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"hash/fnv"
 	"image"
 	"image/color"
+	"image/draw"
 	_ "image/png"
+	"io"
+	"io/fs"
 	"log"
 	"math"
+	"math/rand"
+	"os"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/audio"
 	"github.com/hajimehoshi/ebiten/v2/audio/mp3"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/hajimehoshi/ebiten/v2/vector"
 )
 
@@ -22,11 +36,137 @@ const (
 	screenHeight = 540
 	fontWidth    = 62
 	fontHeight   = 50
+
+	// Position et échelle par défaut du damier à l'écran
+	defaultFloorY      = 260
+	defaultFloorScaleX = 0.6
+	defaultFloorScaleY = 2.6
+
+	// Éclaircissement des caractères en mode contraste élevé
+	highContrastBoost = 1.4
+
+	// Facteur appliqué aux mouvements de fond quand reduceMotion est actif
+	reducedMotionScale = 0.2
+
+	// Durée par défaut d'une forme d'onde des sphères, en secondes
+	animDuration = 7
+
+	defaultBallCount = 4
+	maxBallCount     = 64
+
+	// Nombre d'états conservés pour revenir en arrière image par image
+	stateHistorySize = 300
+
+	// Plage et pas du multiplicateur de rotation réglé avec [ et ]
+	minSpinMultiplier  = 0.1
+	maxSpinMultiplier  = 4
+	spinMultiplierStep = 0.1
+
+	// Pas de quantification de la profondeur pour le tri des boules : deux Z
+	// arrondis au même multiple sont considérés à égalité
+	depthQuantum = 1e-6
 )
 
 //go:embed assets/*
 var assets embed.FS
 
+// ballShaderSrc dessine une sphère éclairée (Lambert, lumière fixe en haut à gauche)
+const ballShaderSrc = `//kage:unit pixels
+
+package main
+
+var Center vec2
+var Radius float
+var BaseColor vec4
+var LightDir vec3
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	d := (dstPos.xy - Center) / Radius
+	r2 := dot(d, d)
+
+	// Bord anticrénelé sur un pixel
+	a := clamp((1-sqrt(r2))*Radius, 0, 1)
+	if a <= 0 {
+		return vec4(0)
+	}
+
+	// Normale de la sphère et éclairage de Lambert
+	n := vec3(d, sqrt(max(0, 1-r2)))
+	lambert := max(dot(n, normalize(LightDir)), 0)
+	c := BaseColor.rgb * (0.25 + 0.75*lambert)
+
+	return vec4(c, 1) * a * color
+}
+`
+
+// invertShaderSrc inverse les couleurs de l'image source (effet négatif)
+const invertShaderSrc = `//kage:unit pixels
+
+package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	c := imageSrc0At(srcPos)
+	// Couleurs prémultipliées : l'inverse de rgb est a - rgb
+	return vec4(c.a-c.rgb, c.a) * color
+}
+`
+
+// floorShaderSrc dessine le damier en perspective de façon procédurale
+const floorShaderSrc = `//kage:unit pixels
+
+package main
+
+var XMove float
+var YMove float
+var Fov float
+var Height float
+var Color vec4
+var FarColor vec4
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	p := dstPos.xy - imageDstOrigin()
+	t := p.y / Height
+
+	// Bandes verticales en perspective (équivalent des quads)
+	period := 32 + 160*t
+	u := (p.x - XMove*(1+5*t) + 800*t) / period
+	stripe := 1 - step(0.25, abs(u-floor(u+0.5)))
+
+	// Bandes horizontales (équivalent du masque)
+	z := 50*Fov/(p.y+20) - Fov + YMove
+	band := 1 - step(0.5, fract(z/64))
+
+	// XOR des deux motifs, assombri avec la distance
+	return mix(FarColor, Color, t) * abs(stripe-band)
+}
+`
+
+// blurShaderSrc applique un flou boîte sur un axe ; deux passes (Dir
+// horizontal puis vertical) donnent le flou complet
+const blurShaderSrc = `//kage:unit pixels
+
+package main
+
+var Radius float
+var Dir vec2
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	sum := vec4(0)
+	n := 0.0
+	// Les bornes d'une boucle Kage doivent être constantes : rayon max 16
+	for i := -16; i <= 16; i++ {
+		if abs(float(i)) <= Radius {
+			sum += imageSrc0At(srcPos + Dir*float(i))
+			n += 1
+		}
+	}
+	return sum / n * color
+}
+`
+
+// maxBlurRadius est le rayon maximal géré par blurShaderSrc
+const maxBlurRadius = 16
+
 // Vec3 représente un vecteur 3D
 type Vec3 struct {
 	X, Y, Z float64
@@ -40,21 +180,78 @@ func (v *Vec3) RotateY(r float64) {
 	v.X = x2
 }
 
+// RotateX effectue une rotation autour de l'axe X
+func (v *Vec3) RotateX(r float64) {
+	y2 := v.Y*math.Cos(r) - v.Z*math.Sin(r)
+	z2 := v.Y*math.Sin(r) + v.Z*math.Cos(r)
+	v.Y = y2
+	v.Z = z2
+}
+
+// RotateAxis effectue une rotation d'angle r autour d'un axe quelconque
+// (formule de Rodrigues). Un axe de longueur nulle laisse le vecteur inchangé.
+func (v *Vec3) RotateAxis(axis Vec3, r float64) {
+	length := math.Sqrt(axis.X*axis.X + axis.Y*axis.Y + axis.Z*axis.Z)
+	if length == 0 {
+		return
+	}
+	kx, ky, kz := axis.X/length, axis.Y/length, axis.Z/length
+
+	cos, sin := math.Cos(r), math.Sin(r)
+	dot := kx*v.X + ky*v.Y + kz*v.Z
+
+	// v*cos + (k x v)*sin + k*(k.v)*(1-cos)
+	x2 := v.X*cos + (ky*v.Z-kz*v.Y)*sin + kx*dot*(1-cos)
+	y2 := v.Y*cos + (kz*v.X-kx*v.Z)*sin + ky*dot*(1-cos)
+	z2 := v.Z*cos + (kx*v.Y-ky*v.X)*sin + kz*dot*(1-cos)
+	v.X, v.Y, v.Z = x2, y2, z2
+}
+
 // Sprite représente un sprite projeté en 3D
 type Sprite struct {
 	U, V, W, Z float64
 }
 
-// NewSprite crée un sprite projeté depuis un point 3D
-func NewSprite(p Vec3, focalLength float64, canvasWidth, canvasHeight int) Sprite {
-	centerX := float64(canvasWidth) / 2
-	centerY := float64(canvasHeight)/2 + 40
+// Camera regroupe les paramètres de projection des sprites 3D
+type Camera struct {
+	FocalLength   float64
+	CenterXOffset float64 // Décalage horizontal du centre de projection
+	CenterYOffset float64 // Décalage vertical du centre de projection sous le milieu de l'écran
+	NearPlane     float64 // Distance minimale devant l'observateur en deçà de laquelle rien n'est dessiné
+	SpriteScale   float64 // Facteur appliqué à l'échelle de projection pour la taille des sprites
+}
+
+// DefaultCamera retourne la caméra d'origine de la démo
+func DefaultCamera() Camera {
+	return Camera{
+		FocalLength:   400,
+		CenterYOffset: 40,
+		NearPlane:     10,
+		SpriteScale:   0.7,
+	}
+}
+
+// Visible indique si un point de profondeur z est derrière le plan proche.
+// Au-delà, l'échelle de projection explose puis devient négative (sprite inversé).
+func (c Camera) Visible(z float64) bool {
+	return c.FocalLength+z > c.NearPlane
+}
+
+// Scale retourne l'échelle de projection à la profondeur z, sans SpriteScale
+func (c Camera) Scale(z float64) float64 {
+	return c.FocalLength / (c.FocalLength + z)
+}
 
-	scale := focalLength / (focalLength + p.Z)
+// Project crée un sprite projeté depuis un point 3D
+func (c Camera) Project(p Vec3, canvasWidth, canvasHeight int) Sprite {
+	centerX := float64(canvasWidth)/2 + c.CenterXOffset
+	centerY := float64(canvasHeight)/2 + c.CenterYOffset
+
+	scale := c.Scale(p.Z)
 	return Sprite{
 		U: p.X*scale + centerX,
 		V: p.Y*scale + centerY,
-		W: scale * 0.7,
+		W: scale * c.SpriteScale,
 		Z: p.Z,
 	}
 }
@@ -67,26 +264,228 @@ type Anim struct {
 	RadiusFromCenterOfScreen float64
 }
 
+// Font décrit une planche de caractères découpée en cellules de taille fixe
+type Font struct {
+	Image      *ebiten.Image
+	CellWidth  int
+	CellHeight int
+	Cols       int
+}
+
+// NewFont crée une font dont le nombre de colonnes est déduit de la largeur de l'image
+func NewFont(img *ebiten.Image, cellWidth, cellHeight int) *Font {
+	return &Font{
+		Image:      img,
+		CellWidth:  cellWidth,
+		CellHeight: cellHeight,
+		Cols:       max(1, img.Bounds().Dx()/cellWidth),
+	}
+}
+
+// measureText retourne la largeur en pixels d'un texte rendu avec cette font,
+// à espacement fixe. Les scrollers passent par Game.textWidth, qui compte
+// aussi la largeur des sprites.
+func (f *Font) measureText(text string) float64 {
+	return float64(len(text)) * float64(f.CellWidth)
+}
+
+// Options regroupe les options de lancement du jeu
+type Options struct {
+	WindowWidth  int
+	WindowHeight int
+	NoAudio      bool
+	Fullscreen   bool
+	BallCount    int
+	ScrollText   string
+	AssetZip     string
+	SampleRate   int    // Fréquence audio en Hz (0 = celle du fichier MP3)
+	IntroFont    string // Font du scroller d'intro (voir fontNames, vide = défaut)
+	MainFont     string // Font du scroller principal (voir fontNames, vide = défaut)
+}
+
+// DefaultOptions retourne les options correspondant au comportement d'origine
+func DefaultOptions() Options {
+	return Options{
+		WindowWidth:  screenWidth,
+		WindowHeight: screenHeight,
+		BallCount:    defaultBallCount,
+		SampleRate:   44100,
+	}
+}
+
+// validate vérifie la cohérence des options
+func (o Options) validate() error {
+	if o.WindowWidth <= 0 || o.WindowHeight <= 0 {
+		return fmt.Errorf("window size must be positive, got %dx%d", o.WindowWidth, o.WindowHeight)
+	}
+	if o.BallCount < 1 || o.BallCount > maxBallCount {
+		return fmt.Errorf("ball count must be between 1 and %d, got %d", maxBallCount, o.BallCount)
+	}
+	if o.SampleRate < 0 {
+		return fmt.Errorf("sample rate must not be negative, got %d", o.SampleRate)
+	}
+	for _, name := range []string{o.IntroFont, o.MainFont} {
+		if name != "" && !validFontName(name) {
+			return fmt.Errorf("unknown font %q, expected one of %v", name, fontNames)
+		}
+	}
+	return nil
+}
+
+// Calques de la scène principale, dans l'ordre de composition
+const (
+	layerBackdrop uint8 = 1 << iota
+	layerMountains
+	layerChessboard
+	layerScroller
+	layerBalls
+
+	layerAll = layerBackdrop | layerMountains | layerChessboard | layerScroller | layerBalls
+)
+
+// layerNames associe chaque calque à son nom pour l'affichage de debug
+var layerNames = []struct {
+	layer uint8
+	name  string
+}{
+	{layerBackdrop, "backdrop"},
+	{layerMountains, "mountains"},
+	{layerChessboard, "chessboard"},
+	{layerScroller, "scroller"},
+	{layerBalls, "balls"},
+}
+
+// LayerEffect décrit un effet appliqué à un seul calque lors de sa composition.
+// La valeur zéro ne modifie rien et le calque est alors dessiné directement.
+type LayerEffect struct {
+	Tint    ebiten.ColorScale
+	OffsetX float64
+	OffsetY float64
+	Blur    int // Rayon du flou en pixels (0 = net)
+}
+
+// Phase identifie la partie de la démo en cours
+type Phase int
+
+const (
+	PhaseIntro Phase = iota // Scroller d'intro sur fond noir
+	PhaseMain               // Scène principale : damier, scroller et boules
+	PhaseOutro              // Logo fixe et message final, jusqu'à la fermeture
+)
+
+// Theme regroupe les réglages de couleurs appliqués ensemble par ApplyTheme
+type Theme struct {
+	Name         string
+	FloorNear    color.RGBA
+	FloorFar     color.RGBA
+	BackdropTint color.RGBA // Multiplie les couleurs du fond
+	ScrollTint   color.RGBA // Multiplie les couleurs du scroller principal
+	FogStrength  float64
+	BallAdditive bool
+}
+
+// themes liste les thèmes parcourus avec la touche T ; le premier correspond
+// au rendu d'origine
+var themes = []Theme{
+	{
+		Name:         "classic grey",
+		FloorNear:    color.RGBA{96, 96, 96, 255},
+		FloorFar:     color.RGBA{96, 96, 96, 255},
+		BackdropTint: color.RGBA{255, 255, 255, 255},
+		ScrollTint:   color.RGBA{255, 255, 255, 255},
+	},
+	{
+		Name:         "neon",
+		FloorNear:    color.RGBA{40, 220, 255, 255},
+		FloorFar:     color.RGBA{120, 20, 160, 255},
+		BackdropTint: color.RGBA{90, 60, 160, 255},
+		ScrollTint:   color.RGBA{255, 120, 255, 255},
+		FogStrength:  0.4,
+		BallAdditive: true,
+	},
+	{
+		Name:         "sunset",
+		FloorNear:    color.RGBA{200, 110, 60, 255},
+		FloorFar:     color.RGBA{90, 40, 60, 255},
+		BackdropTint: color.RGBA{255, 160, 110, 255},
+		ScrollTint:   color.RGBA{255, 220, 150, 255},
+		FogStrength:  0.6,
+	},
+}
+
+// FrameMetrics contient le temps passé dans chaque sous-système de rendu
+// pour une image. Il s'agit du temps CPU d'émission des commandes de dessin,
+// le GPU travaillant de façon asynchrone.
+type FrameMetrics struct {
+	Chessboard time.Duration
+	Scroller   time.Duration
+	Doc        time.Duration
+}
+
 // Game représente l'état du jeu
 type Game struct {
 	// Images
 	backdrop  *ebiten.Image
 	mountains *ebiten.Image
-	font1     *ebiten.Image
-	fontIn    *ebiten.Image
-	fontOut   *ebiten.Image
+	font1     *Font
+	fontIn    *Font // Variante de font_out sur la même grille, sélectionnable par nom ("in")
+	fontOut   *Font
+	introFont *Font // Font du scroller d'intro (font1 par défaut)
+	mainFont  *Font // Font du scroller principal (fontOut par défaut)
 	sphere    *ebiten.Image
+	spheres   []*ebiten.Image // Textures attribuées aux boules à tour de rôle
 	shadows   [4]*ebiten.Image
 
+	// Fenêtre ouverte par Run, modifiable avant son appel
+	WindowTitle  string
+	WindowWidth  int
+	WindowHeight int
+	fullscreen   bool
+
+	// Résolution logique de rendu
+	width       int
+	height      int
+	scrollWidth int
+
 	// Canvas virtuels
 	chessboard     *ebiten.Image
 	chessboardMask *ebiten.Image
 	scrollCanvas1  *ebiten.Image
 	scrollCanvas2  *ebiten.Image
-	scrollCanvas3  *ebiten.Image
 	scrollCanvas4  *ebiten.Image
 	scrollCanvas5  *ebiten.Image
 
+	// Shaders
+	floorShader     *ebiten.Shader
+	useShaderFloor  bool
+	ballShader      *ebiten.Shader
+	useShaderBalls  bool
+	shaderBallColor color.RGBA
+	invertShader    *ebiten.Shader
+	blurShader      *ebiten.Shader
+	blurTemp        *ebiten.Image // Résultat de la passe horizontale du flou
+	blurImage       *ebiten.Image
+	invert          bool // Effet négatif sur l'image finale
+
+	// Tampon de rendu hors écran
+	offscreen *ebiten.Image
+
+	// Effets par calque, rendus dans un tampon commun avant composition
+	layerEffects map[uint8]LayerEffect
+	layerImage   *ebiten.Image
+
+	// Post-effets
+	vignetteImage    *ebiten.Image
+	vignette         bool
+	vignetteStrength float64
+	scanlineImage    *ebiten.Image
+	scanlines        bool
+	scanlineSpacing  int
+	scanlineDarkness float64
+	brightness       float64 // Luminosité globale, appliquée à l'image finale (1 = inchangée)
+	spotlightImage   *ebiten.Image
+	spotlight        bool // Projecteur centré sur les sphères, le reste assombri
+
 	// Variables d'animation
 	vbl   float64
 	vbl2  float64
@@ -99,9 +498,45 @@ type Game struct {
 	fov   float64
 	speed float64
 
+	// Balancement de la caméra (via le déplacement du damier)
+	swayAmplitude float64
+	swayFrequency float64
+	reduceMotion  bool
+
+	// Parallaxe des montagnes (0 = statique)
+	parallaxFactor float64
+	mountainsX     float64
+
+	// Couleurs du damier au premier plan et au fond, en dégradé
+	floorNearColor color.RGBA
+	floorFarColor  color.RGBA
+	floorFillRule  ebiten.FillRule
+
+	// Combinaison des bandes et du masque des rangées (Xor : damier d'origine)
+	floorCompositeMode ebiten.CompositeMode
+
+	// Échelle et position verticale du damier à l'écran
+	floorScaleX float64
+	floorScaleY float64
+	floorY      float64
+
+	// Thème de couleurs courant (index dans themes)
+	themeIndex   int
+	backdropTint color.RGBA
+	scrollTint   color.RGBA
+
+	// Damier : false pour n'afficher que les bandes verticales, sans le masque XOR
+	floorCheckered bool
+
 	// Scroll precalc
-	scrollX    []float64
-	scrollXMod int
+	scrollX             []float64
+	scrollXMod          int
+	scrollTable         []float64 // Table de vague chargée depuis les assets (nil = calculée)
+	symmetricWave       bool
+	scrollWaveAmplitude float64
+
+	// Sprites du scroller principal, indexés par leur octet sentinelle
+	glyphs map[byte]*ebiten.Image
 
 	// Scrolltext
 	text1    string
@@ -110,40 +545,327 @@ type Game struct {
 	scrollX2 float64
 	scrollX3 float64
 
+	// Position verticale des scrollers à l'écran
+	introScrollY float64
+	mainScrollY  float64
+
+	scrollReverse     bool    // Inverse le sens du scroller principal
+	scrollerPaused    bool    // Fige les scrollers sans arrêter le reste de la scène
+	introSpeed        float64 // Avance du texte d'intro par Update, en pixels
+	mainSpeed         float64 // Avance du scroller principal par image, en pixels
+	highContrast      bool
+	showScroller      bool       // false : scène principale sans scroller, texte figé
+	showIntroScroller bool       // false : intro sans texte, qui défile quand même jusqu'au saut
+	highContrastColor color.RGBA // Couleur prémultipliée de la bande derrière le texte
+
+	// File de messages du scroller principal
+	scrollQueue           []string
+	scrollQueueIndex      int // -1 quand aucun message de la file n'est en cours
+	scrollQueueEnd        float64
+	scrollQueueReverseEnd float64
+	scrollQueueLoop       bool
+	introTitle            string // Titre fixe affiché pendant l'intro (vide = aucun)
+
+	// Bande pré-rendue du scroller d'intro, redessinée quand le texte, la font
+	// ou le style (ombre, contour) changent
+	cacheIntroScroll  bool
+	introStrip        *ebiten.Image
+	introStripChar    int
+	introStripText    string
+	introStripFont    *Font
+	introStripShadow  bool
+	introStripOutline bool
+
+	// Apparition des lettres de l'intro : fondu de fontIn vers fontOut selon la
+	// position à l'écran (désactivé par défaut, intro à une seule font)
+	introReveal bool
+
+	// Ombre portée du texte
+	scrollShadow       bool
+	scrollShadowOffset float64
+	scrollShadowColor  color.RGBA
+
+	// Contour du texte (8 passes supplémentaires, désactivé par défaut)
+	scrollOutline      bool
+	scrollOutlineWidth float64
+	scrollOutlineColor color.RGBA
+
 	// 3D Doc animation
-	currentRadians             float64
-	overWriteFirstTwoWaveforms bool
-	startTime                  time.Time
+	camera          Camera
+	shadowScale     float64 // Taille des ombres relative à l'échelle de projection
+	shadowLift      float64 // Décalage vertical maximal des ombres, en pixels
+	shadowLiftCurve float64 // Exposant de la courbe de décalage (1 = linéaire)
+
+	blendDuration          float64   // Durée de transition entre deux formes d'onde, en secondes
+	animDurations          []float64 // Durée de chacune des 8 formes d'onde, en secondes
+	groundY                float64   // Hauteur du sol où sont projetées les ombres
+	ballSwayCoupling       float64   // Fraction du balancement appliquée aux boules (0 = indépendantes)
+	currentRadians         float64
+	animTime               float64 // Horloge de l'animation en secondes, avancée par Update
+	ballCount              int
+	fogStrength            float64 // 0 = pas de brouillard
+	secondRing             bool
+	secondRingRadiusOffset float64
+	secondRingRadians      float64
+	clampToFloor           bool // Empêche les boules de s'enfoncer sous le sol
+	linearFilter           bool // Filtrage linéaire des sprites de boules et d'ombres
+	continuousShadow       bool // Ombre unique atténuée avec la distance plutôt que 4 niveaux
+	ballAdditive           bool // Sphères en mélange additif, effet lumineux là où elles se chevauchent
+
+	ringTilt float64 // Inclinaison du plan de l'anneau autour de l'axe X, en radians
+
+	spinSmoothing  float64 // Lissage de la vitesse de rotation, de 0 (aucun) à 1 exclu
+	spinMultiplier float64 // Facteur appliqué à la vitesse de rotation de l'anneau
+	smoothedSpin   float64
+
+	buildUp         bool    // Les boules apparaissent une à une au début de la scène principale
+	buildUpDuration float64 // Durée de la mise en place, en secondes
+	floorReflection bool
 
 	// Audio
-	audioContext *audio.Context
-	audioPlayer  *audio.Player
+	noAudio       bool
+	sampleRate    int // 0 = fréquence du fichier MP3
+	audioContext  *audio.Context
+	audioPlayer   *audio.Player
+	musicFadeIn   float64 // Durée du fondu d'entrée de la musique en secondes (0 = aucun)
+	musicTime     float64 // Temps écoulé depuis le début de la lecture
+	musicLoopFade float64 // Durée du fondu de la musique autour du point de boucle (0 = aucun)
+
+	// Fenêtre réduite : le rendu est sauté, seule l'horloge continue
+	minimized           bool
+	layoutEmpty         bool // Dernier Layout reçu avec une taille nulle
+	pauseAudioMinimized bool // Suspend la musique tant que la fenêtre est réduite
 
 	// Phases
-	jump bool
+	phase        Phase
+	introTimeout float64 // Durée max de l'intro en secondes (0 = pas de limite)
+	jumpTime     float64 // Valeur de animTime au passage à la scène principale
+	outro        bool    // À la fin de la boucle, passer à l'outro au lieu de redémarrer
+	outroText    string
+	outroTime    float64 // Valeur de animTime au passage à l'outro
+	logo         *ebiten.Image
+	speedEaseIn  float64 // Durée de la montée en vitesse du damier après le saut, en secondes
+
+	// Calques visibles (un bit par calque)
+	layerMask uint8
+
+	// Debug : trajectoires des boules sur la forme d'onde courante
+	showPaths bool
+
+	// Debug : grille de coordonnées pour la mise en page
+	showGrid bool
+
+	// Mode photo : image figée que l'on peut zoomer et déplacer
+	photoMode     bool
+	photoFrame    *ebiten.Image
+	photoCaptured bool
+	photoZoom     float64
+	photoPanX     float64
+	photoPanY     float64
+	photoDragX    int
+	photoDragY    int
+
+	// Pause avec avance et retour image par image
+	paused        bool
+	pauseFrame    *ebiten.Image
+	pauseCaptured bool
+	stateHistory  []gameState // Tampon circulaire des états, alloué au premier tick
+	stateNext     int         // Prochaine case écrite dans stateHistory
+	stateCount    int         // Nombre d'états conservés
+
+	// Boucle de la démo (loopDuration = 0 : infinie)
+	loopDuration    float64
+	loopFade        float64
+	loopReplayIntro bool
+	looped          bool
+
+	closed bool
+
+	// Pack d'assets optionnel, prioritaire sur les assets embarqués
+	assetZip *zip.ReadCloser
+
+	// Journalisation
+	logger          *log.Logger
+	metricsCallback func(FrameMetrics)
+
+	// Générateur aléatoire unique, pour des effets reproductibles
+	rng *rand.Rand
 }
 
 // NewGame crée une nouvelle instance du jeu
-func NewGame() *Game {
+func NewGame(opts Options) *Game {
 	g := &Game{
-		xm:                         0,
-		ym:                         315,
-		fov:                        250,
-		speed:                      1,
-		overWriteFirstTwoWaveforms: true,
-		startTime:                  time.Now(),
+		xm:                     0,
+		ym:                     315,
+		fov:                    250,
+		speed:                  1,
+		swayAmplitude:          128,
+		swayFrequency:          1.0 / 40,
+		WindowTitle:            "TCB 3D DOC Demo - Go/Ebiten",
+		WindowWidth:            opts.WindowWidth,
+		WindowHeight:           opts.WindowHeight,
+		fullscreen:             opts.Fullscreen,
+		width:                  screenWidth,
+		height:                 screenHeight,
+		camera:                 DefaultCamera(),
+		buildUpDuration:        8,
+		shadowScale:            0.7,
+		shadowLift:             26,
+		shadowLiftCurve:        1,
+		blendDuration:          1.25, // 1/0.8 s, la transition d'origine
+		animDurations:          []float64{animDuration, animDuration, animDuration, animDuration, animDuration, animDuration, animDuration, animDuration},
+		groundY:                60,
+		shaderBallColor:        color.RGBA{220, 20, 20, 255},
+		ballCount:              opts.BallCount,
+		noAudio:                opts.NoAudio,
+		sampleRate:             opts.SampleRate,
+		secondRingRadiusOffset: -60,
+		vignetteStrength:       0.8,
+		loopFade:               1,
+		introTimeout:           60,
+		speedEaseIn:            1,
+		scrollWaveAmplitude:    1,
+		introScrollY:           62,
+		mainScrollY:            62,
+		highContrastColor:      color.RGBA{0, 0, 0, 200},
+		scrollQueueLoop:        true,
+		scrollQueueIndex:       -1,
+		showScroller:           true,
+		showIntroScroller:      true,
+		introSpeed:             5,
+		spinMultiplier:         1,
+		mainSpeed:              3,
+		scrollShadowOffset:     4,
+		scrollShadowColor:      color.RGBA{0, 0, 0, 160},
+		scrollOutlineWidth:     2,
+		scrollOutlineColor:     color.RGBA{0, 0, 0, 255},
+		layerMask:              layerAll,
+		floorCheckered:         true,
+		floorCompositeMode:     ebiten.CompositeModeXor,
+		floorFillRule:          ebiten.FillAll,
+		floorScaleX:            defaultFloorScaleX,
+		floorScaleY:            defaultFloorScaleY,
+		floorY:                 defaultFloorY,
+		loopReplayIntro:        true,
+		scanlineSpacing:        2,
+		scanlineDarkness:       0.3,
+		brightness:             1,
+		logger:                 log.Default(),
+		rng:                    rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+
+	if g.ballCount < 1 {
+		g.ballCount = defaultBallCount
+	}
+	g.ApplyTheme(themes[0])
+	if g.WindowWidth <= 0 || g.WindowHeight <= 0 {
+		g.WindowWidth, g.WindowHeight = screenWidth, screenHeight
 	}
 
 	// Textes
 	g.text1 = "               BILIZIR FROM DMA HAVE DONE IT AGAIN: A NEW GOLANG/EBITEN CONVERSION, THIS TIME THIS IS THE 3D-DOC FROM TCB    \\          "
 	g.text2 = "                          BILIZIR IS PROUD TO PRESENT THE CONVERSION OF THE 3D-DOC DEMO!    THIS SCREEN WAS ORIGINALLY RELEASED IN TCB'S CUDDLY DEMOS ON ATARI ST A LONG TIME AGO...  HERE IT'S THE GOLANG VERSION OF THE 3D-DOC WELL IT'S A FREE ADAPTATION :)   GREETINGS TO ALL MEMBERS OF DMA AND THE UNION... LET'S WRAP!   "
+	g.outroText = "          THANKS FOR WATCHING...   SEE YOU IN THE NEXT CONVERSION!          "
+	if opts.ScrollText != "" {
+		g.text2 = opts.ScrollText
+	}
 
 	return g
 }
 
+// SetLogger remplace le logger utilisé pour les messages du jeu (nil pour les ignorer)
+func (g *Game) SetLogger(l *log.Logger) {
+	if l == nil {
+		l = log.New(io.Discard, "", 0)
+	}
+	g.logger = l
+}
+
+// SetSeed réinitialise le générateur aléatoire avec une graine fixe
+func (g *Game) SetSeed(seed int64) {
+	g.rng = rand.New(rand.NewSource(seed))
+}
+
+// SetMetricsCallback enregistre une fonction appelée à chaque image de la
+// scène principale avec les temps de rendu (nil pour désactiver)
+func (g *Game) SetMetricsCallback(cb func(FrameMetrics)) {
+	g.metricsCallback = cb
+}
+
+// OpenAssetZip utilise une archive zip comme pack d'assets prioritaire sur les
+// assets embarqués. Les fichiers y sont cherchés avec ou sans le préfixe
+// "assets/". Si l'archive n'existe pas, les assets embarqués restent utilisés.
+func (g *Game) OpenAssetZip(path string) error {
+	r, err := zip.OpenReader(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		g.logger.Printf("Asset pack %s not found, using embedded assets", path)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open asset pack: %v", err)
+	}
+
+	if g.assetZip != nil {
+		g.assetZip.Close()
+	}
+	g.assetZip = r
+	return nil
+}
+
+// zipAssetPath retourne le nom sous lequel path est présent dans le pack zip
+func (g *Game) zipAssetPath(path string) (string, bool) {
+	if g.assetZip == nil {
+		return "", false
+	}
+	for _, name := range []string{path, strings.TrimPrefix(path, "assets/")} {
+		if _, err := fs.Stat(g.assetZip, name); err == nil {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// readAsset lit un fichier depuis le pack zip s'il le contient, sinon depuis les assets embarqués
+func (g *Game) readAsset(path string) ([]byte, error) {
+	if name, ok := g.zipAssetPath(path); ok {
+		return fs.ReadFile(g.assetZip, name)
+	}
+	return assets.ReadFile(path)
+}
+
+// assetExists indique si un fichier est disponible dans le pack zip ou les assets embarqués
+func (g *Game) assetExists(path string) bool {
+	if _, ok := g.zipAssetPath(path); ok {
+		return true
+	}
+	_, err := fs.Stat(assets, path)
+	return err == nil
+}
+
+// SetReduceMotion atténue la vague et le rebond du scroller ainsi que les
+// mouvements de caméra, pour les personnes sensibles au mal des transports
+func (g *Game) SetReduceMotion(reduce bool) {
+	g.reduceMotion = reduce
+}
+
+// motionScale retourne le facteur appliqué aux amplitudes de mouvement
+func (g *Game) motionScale() float64 {
+	if g.reduceMotion {
+		return reducedMotionScale
+	}
+	return 1
+}
+
+// SetCameraSway règle l'amplitude et la fréquence (par unité de vbl2) du
+// balancement de la caméra. Les valeurs d'origine sont 128 et 1/40.
+func (g *Game) SetCameraSway(amplitude, frequency float64) {
+	g.swayAmplitude = amplitude
+	g.swayFrequency = frequency
+}
+
 // loadImage charge une image depuis les assets
 func (g *Game) loadImage(path string) (*ebiten.Image, error) {
-	data, err := assets.ReadFile(path)
+	data, err := g.readAsset(path)
 	if err != nil {
 		return nil, err
 	}
@@ -153,11 +875,143 @@ func (g *Game) loadImage(path string) (*ebiten.Image, error) {
 		return nil, err
 	}
 
-	return ebiten.NewImageFromImage(img), nil
+	return ebiten.NewImageFromImage(premultiplied(img)), nil
+}
+
+// premultiplied convertit img en *image.RGBA, dont l'alpha est prémultiplié
+// comme l'attend ebiten. Le décodeur PNG produit des *image.NRGBA (alpha non
+// prémultiplié) pour les sprites transparents : draw.Draw fait la conversion,
+// sans laquelle les bords mis à l'échelle montreraient un halo sombre.
+func premultiplied(img image.Image) *image.RGBA {
+	if rgba, ok := img.(*image.RGBA); ok {
+		return rgba
+	}
+
+	b := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, b.Min, draw.Src)
+	return rgba
+}
+
+// newVignetteImage génère un dégradé radial transparent au centre et sombre sur
+// les bords. inner et outer bornent la transition, en fraction de la demi-diagonale.
+func newVignetteImage(w, h int, inner, outer float64) *ebiten.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	cx, cy := float64(w)/2, float64(h)/2
+	maxDist := math.Hypot(cx, cy)
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			d := math.Hypot(float64(x)+0.5-cx, float64(y)+0.5-cy) / maxDist
+			a := math.Max(0, math.Min(1, (d-inner)/(outer-inner)))
+			img.SetRGBA(x, y, color.RGBA{0, 0, 0, uint8(a * a * 255)})
+		}
+	}
+
+	return ebiten.NewImageFromImage(img)
+}
+
+// newScanlineImage génère des lignes horizontales opaques espacées de spacing pixels
+func newScanlineImage(w, h, spacing int) *ebiten.Image {
+	img := ebiten.NewImage(w, h)
+	for y := spacing - 1; y < h; y += spacing {
+		vector.DrawFilledRect(img, 0, float32(y), float32(w), 1, color.Black, false)
+	}
+	return img
+}
+
+// SetScanlines configure l'espacement et l'opacité des lignes de balayage
+func (g *Game) SetScanlines(spacing int, darkness float64) {
+	g.scanlineSpacing = max(1, spacing)
+	g.scanlineDarkness = math.Max(0, math.Min(1, darkness))
+
+	// Régénérer le calque s'il a déjà été créé
+	if g.scanlineImage != nil {
+		g.scanlineImage.Dispose()
+		g.scanlineImage = newScanlineImage(g.width, g.height, g.scanlineSpacing)
+	}
+}
+
+// ApplyTheme applique les couleurs, le brouillard et le mélange des sphères d'un thème
+func (g *Game) ApplyTheme(t Theme) {
+	g.floorNearColor, g.floorFarColor = t.FloorNear, t.FloorFar
+	g.backdropTint = t.BackdropTint
+	g.scrollTint = t.ScrollTint
+	g.fogStrength = t.FogStrength
+	g.ballAdditive = t.BallAdditive
+}
+
+// cycleTheme passe au thème suivant de la liste
+func (g *Game) cycleTheme() {
+	g.themeIndex = (g.themeIndex + 1) % len(themes)
+	g.ApplyTheme(themes[g.themeIndex])
+}
+
+// SetBrightness règle la luminosité de l'image finale (1 = inchangée)
+func (g *Game) SetBrightness(b float64) {
+	g.brightness = math.Max(0, b)
+}
+
+// brightnessScale retourne le ColorScale appliqué à l'image finale : les
+// composantes de couleur sont multipliées par la luminosité, l'alpha reste intact
+func (g *Game) brightnessScale() ebiten.ColorScale {
+	var cs ebiten.ColorScale
+	b := float32(g.brightness)
+	cs.Scale(b, b, b, 1)
+	return cs
+}
+
+// loadFont charge une planche de caractères depuis les assets
+func (g *Game) loadFont(path string, cellWidth, cellHeight int) (*Font, error) {
+	img, err := g.loadImage(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewFont(img, cellWidth, cellHeight), nil
+}
+
+// loadScrollTable charge une table de vague (tableau JSON de décalages) qui
+// remplace les motifs calculés par precalcScrollX
+func (g *Game) loadScrollTable(path string) error {
+	data, err := g.readAsset(path)
+	if err != nil {
+		return err
+	}
+
+	var table []float64
+	if err := json.Unmarshal(data, &table); err != nil {
+		return err
+	}
+	if len(table) == 0 {
+		return fmt.Errorf("scroll table %s is empty", path)
+	}
+	g.scrollTable = table
+	return nil
+}
+
+// DumpScrollTable écrit dans path la table de vague courante au format JSON,
+// rechargeable depuis assets/scroll_table.json. La table écrite inclut déjà
+// l'amplitude et la symétrie : la recharger avec une amplitude de 1.
+func (g *Game) DumpScrollTable(path string) error {
+	data, err := json.MarshalIndent(g.scrollX, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode scroll table: %v", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write scroll table: %v", err)
+	}
+	return nil
 }
 
-// precalcScrollX précalcule les valeurs de déplacement du scroll
+// precalcScrollX précalcule les valeurs de déplacement du scroll, à partir de
+// la table chargée si elle existe
 func (g *Game) precalcScrollX() {
+	if g.scrollTable != nil {
+		g.scrollX = append([]float64(nil), g.scrollTable...)
+		g.applyScrollWave()
+		return
+	}
+
 	g.scrollX = make([]float64, 0, 1024)
 
 	// Premier pattern
@@ -186,9 +1040,164 @@ func (g *Game) precalcScrollX() {
 		g.scrollX = append(g.scrollX, 30*math.Sin(float64(i)*stp1))
 	}
 
+	g.applyScrollWave()
+}
+
+// applyScrollWave applique l'amplitude et la variante symétrique à la table
+func (g *Game) applyScrollWave() {
+	for i := range g.scrollX {
+		g.scrollX[i] *= g.scrollWaveAmplitude
+	}
+
+	// Variante symétrique : la seconde moitié rejoue la première à l'envers,
+	// sans changer la longueur de la table
+	if g.symmetricWave {
+		n := len(g.scrollX)
+		for i := (n + 1) / 2; i < n; i++ {
+			g.scrollX[i] = g.scrollX[n-1-i]
+		}
+	}
+
 	g.scrollXMod = len(g.scrollX)
 }
 
+// SetIntroReveal active l'apparition des lettres de l'intro par fondu de
+// fontIn vers fontOut, à la place de la font unique de l'intro
+func (g *Game) SetIntroReveal(on bool) {
+	g.introReveal = on
+}
+
+// SetFloorPlacement change l'échelle horizontale et verticale du damier ainsi
+// que sa position verticale à l'écran. Les valeurs sont celles de la résolution
+// d'origine et suivent ensuite la taille de rendu.
+func (g *Game) SetFloorPlacement(scaleX, scaleY, y float64) {
+	g.floorScaleX, g.floorScaleY, g.floorY = scaleX, scaleY, y
+}
+
+// SetShadowLift règle le décalage vertical maximal des ombres et l'exposant
+// de sa courbe (1 pour la montée linéaire d'origine)
+func (g *Game) SetShadowLift(lift, curve float64) {
+	g.shadowLift, g.shadowLiftCurve = lift, curve
+}
+
+// SetRingTilt incline le plan de l'anneau de radians autour de l'axe X pour le
+// voir en biais plutôt que par la tranche. Les ombres restent au sol.
+func (g *Game) SetRingTilt(radians float64) {
+	g.ringTilt = radians
+}
+
+// SetIntroSpeed règle l'avance du texte d'intro à chaque Update, en pixels
+// (5 par défaut, la vitesse d'origine). Les positions des caractères ne sont
+// pas arrondies : une vitesse fractionnaire donne un défilement fluide.
+func (g *Game) SetIntroSpeed(speed float64) {
+	g.introSpeed = speed
+}
+
+// SetMainSpeed règle l'avance du scroller principal à chaque Update, en pixels
+func (g *Game) SetMainSpeed(speed float64) {
+	g.mainSpeed = speed
+}
+
+// SetSpinMultiplier règle le facteur appliqué à la vitesse de rotation de
+// l'anneau, borné à [minSpinMultiplier, maxSpinMultiplier]
+func (g *Game) SetSpinMultiplier(m float64) {
+	g.spinMultiplier = math.Max(minSpinMultiplier, math.Min(maxSpinMultiplier, m))
+}
+
+// SetFloorCompositeMode choisit comment le masque des rangées est combiné aux
+// bandes du damier (CompositeModeXor pour le damier d'origine)
+func (g *Game) SetFloorCompositeMode(mode ebiten.CompositeMode) {
+	g.floorCompositeMode = mode
+}
+
+// SetSymmetricWave choisit la table de vague symétrique ou celle d'origine
+func (g *Game) SetSymmetricWave(on bool) {
+	g.symmetricWave = on
+
+	// Recalculer la table si Init l'a déjà construite
+	if g.scrollX != nil {
+		g.precalcScrollX()
+	}
+}
+
+// SetScrollWaveAmplitude multiplie l'amplitude de la vague du scroller
+// (1 = d'origine). Les canvas sont élargis si la vague dépasse leurs marges.
+func (g *Game) SetScrollWaveAmplitude(scale float64) {
+	g.scrollWaveAmplitude = scale
+
+	if g.scrollX != nil {
+		g.precalcScrollX()
+		if g.scrollCanvas1 != nil && g.scrollCanvasWidth() != g.scrollWidth {
+			g.createScreenImages()
+		}
+	}
+}
+
+// scrollCanvasWidth retourne la largeur des canvas du scroller principal, qui
+// garde de chaque côté une marge d'au moins 128 pixels couvrant le décalage
+// maximal de la vague (2 fois la valeur de la table)
+func (g *Game) scrollCanvasWidth() int {
+	margin := 128.0
+	for _, v := range g.scrollX {
+		margin = math.Max(margin, math.Ceil(2*math.Abs(v)))
+	}
+	return max(1024, g.width+2*int(margin))
+}
+
+// createScreenImages crée les canvas et calques dont la taille dépend de la résolution
+func (g *Game) createScreenImages() {
+	for _, img := range []*ebiten.Image{
+		g.scrollCanvas1, g.scrollCanvas2, g.scrollCanvas4, g.scrollCanvas5,
+		g.vignetteImage, g.scanlineImage, g.spotlightImage,
+	} {
+		if img != nil {
+			img.Dispose()
+		}
+	}
+
+	g.scrollWidth = g.scrollCanvasWidth()
+
+	g.scrollCanvas1 = ebiten.NewImage(g.width, 50)
+	g.scrollCanvas2 = ebiten.NewImage(g.scrollWidth, 50)  // Plus large pour les déformations
+	g.scrollCanvas4 = ebiten.NewImage(g.scrollWidth, 50)  // Plus large pour les déformations
+	g.scrollCanvas5 = ebiten.NewImage(g.scrollWidth, 120) // Plus large pour les déformations
+
+	// Générer les calques de post-effets
+	g.vignetteImage = newVignetteImage(g.width, g.height, 0.4, 1)
+	// Deux fois la taille de l'écran : le calque le couvre où que soit son centre
+	g.spotlightImage = newVignetteImage(2*g.width, 2*g.height, 0.1, 0.4)
+	g.scanlineImage = newScanlineImage(g.width, g.height, g.scanlineSpacing)
+}
+
+// SetResolution change la taille logique de rendu. La composition verticale
+// (scrollers, damier, boules) est mise à l'échelle de la nouvelle hauteur.
+func (g *Game) SetResolution(w, h int) {
+	if w <= 0 || h <= 0 {
+		return
+	}
+	g.width, g.height = w, h
+
+	// Recréer les images si Init a déjà été appelé
+	if g.scrollCanvas1 != nil {
+		g.createScreenImages()
+	}
+}
+
+// layoutScaleY retourne le rapport entre la hauteur de rendu et celle
+// d'origine : les positions verticales (scrollers, damier, projection des
+// boules) sont exprimées pour screenHeight et suivent ce facteur
+func (g *Game) layoutScaleY() float64 {
+	return float64(g.height) / screenHeight
+}
+
+// project projette p avec cam sur la zone de rendu, la hauteur de projection
+// suivant layoutScaleY
+func (g *Game) project(cam Camera, p Vec3) Sprite {
+	s := cam.Project(p, g.width, screenHeight)
+	s.V *= g.layoutScaleY()
+	return s
+}
+
 // Init initialise les ressources
 func (g *Game) Init() error {
 	var err error
@@ -204,26 +1213,54 @@ func (g *Game) Init() error {
 		return fmt.Errorf("failed to load mountains: %v", err)
 	}
 
-	g.font1, err = g.loadImage("assets/kh6.png")
+	g.font1, err = g.loadFont("assets/kh6.png", fontWidth, fontHeight)
 	if err != nil {
 		return fmt.Errorf("failed to load font1: %v", err)
 	}
 
-	g.fontIn, err = g.loadImage("assets/font_in.png")
+	g.fontIn, err = g.loadFont("assets/font_in.png", fontWidth, fontHeight)
 	if err != nil {
 		return fmt.Errorf("failed to load fontIn: %v", err)
 	}
 
-	g.fontOut, err = g.loadImage("assets/font_out.png")
+	g.fontOut, err = g.loadFont("assets/font_out.png", fontWidth, fontHeight)
 	if err != nil {
 		return fmt.Errorf("failed to load fontOut: %v", err)
 	}
 
+	// Fonts des scrollers, sauf si déjà choisies
+	if g.introFont == nil {
+		g.introFont = g.font1
+	}
+	if g.mainFont == nil {
+		g.mainFont = g.fontOut
+	}
+
+	// Sprites intégrés au scroller principal, seulement si le texte les utilise
+	g.registerBuiltinGlyphs(g.text2)
+
 	g.sphere, err = g.loadImage("assets/ball.png")
 	if err != nil {
 		return fmt.Errorf("failed to load sphere: %v", err)
 	}
 
+	// Charger les variantes ball0.png, ball1.png... si elles existent
+	g.spheres = nil
+	for i := 0; ; i++ {
+		path := fmt.Sprintf("assets/ball%d.png", i)
+		if !g.assetExists(path) {
+			break
+		}
+		img, err := g.loadImage(path)
+		if err != nil {
+			return fmt.Errorf("failed to load sphere %d: %v", i, err)
+		}
+		g.spheres = append(g.spheres, img)
+	}
+	if len(g.spheres) == 0 {
+		g.spheres = []*ebiten.Image{g.sphere}
+	}
+
 	// Charger les ombres
 	for i := 0; i < 4; i++ {
 		g.shadows[i], err = g.loadImage(fmt.Sprintf("assets/shadow%d.png", i+1))
@@ -232,28 +1269,83 @@ func (g *Game) Init() error {
 		}
 	}
 
+	// Logo de l'outro, optionnel
+	if g.assetExists("assets/logo.png") {
+		g.logo, err = g.loadImage("assets/logo.png")
+		if err != nil {
+			return fmt.Errorf("failed to load logo: %v", err)
+		}
+	}
+
 	// Créer les canvas virtuels
 	g.chessboard = ebiten.NewImage(1280, 80)
 	g.chessboardMask = ebiten.NewImage(1280, 80)
-	g.scrollCanvas1 = ebiten.NewImage(768, 50)
-	g.scrollCanvas2 = ebiten.NewImage(1024, 50)  // Plus large pour les déformations
-	g.scrollCanvas3 = ebiten.NewImage(1024, 50)  // Plus large pour les déformations
-	g.scrollCanvas4 = ebiten.NewImage(1024, 50)  // Plus large pour les déformations
-	g.scrollCanvas5 = ebiten.NewImage(1024, 120) // Plus large pour les déformations
-
-	// Précalculer les valeurs de scroll
-	g.precalcScrollX()
 
-	// Initialiser l'audio
-	g.audioContext = audio.NewContext(44100)
+	// Table de vague personnalisée, optionnelle
+	if g.assetExists("assets/scroll_table.json") {
+		if err := g.loadScrollTable("assets/scroll_table.json"); err != nil {
+			return fmt.Errorf("failed to load scroll table: %v", err)
+		}
+	}
+
+	// Précalculer les valeurs de scroll, dont dépend la largeur des canvas
+	g.precalcScrollX()
+	g.createScreenImages()
+
+	// Compiler les shaders
+	g.floorShader, err = ebiten.NewShader([]byte(floorShaderSrc))
+	if err != nil {
+		return fmt.Errorf("failed to compile floor shader: %v", err)
+	}
+
+	g.ballShader, err = ebiten.NewShader([]byte(ballShaderSrc))
+	if err != nil {
+		return fmt.Errorf("failed to compile ball shader: %v", err)
+	}
+
+	g.invertShader, err = ebiten.NewShader([]byte(invertShaderSrc))
+	if err != nil {
+		return fmt.Errorf("failed to compile invert shader: %v", err)
+	}
+
+	g.blurShader, err = ebiten.NewShader([]byte(blurShaderSrc))
+	if err != nil {
+		return fmt.Errorf("failed to compile blur shader: %v", err)
+	}
+
+	if g.noAudio {
+		return nil
+	}
 
 	// Charger la musique MP3
-	musicData, err := assets.ReadFile("assets/music.mp3")
+	musicData, err := g.readAsset("assets/music.mp3")
 	if err != nil {
-		fmt.Printf("Music not found (optional): %v\n", err)
-	} else {
+		g.logger.Printf("Music not found (optional): %v", err)
+	}
+
+	// Fréquence du contexte : celle demandée, sinon celle du fichier
+	rate := g.sampleRate
+	if rate <= 0 && musicData != nil {
+		if s, err := mp3.DecodeWithoutResampling(bytes.NewReader(musicData)); err == nil {
+			rate = s.SampleRate()
+		}
+	}
+	if rate <= 0 {
+		rate = 44100
+	}
+
+	// Initialiser l'audio. Le contexte est unique par processus : s'il existe
+	// déjà avec une autre fréquence, la musique est rééchantillonnée pour lui.
+	g.audioContext = audio.CurrentContext()
+	if g.audioContext == nil {
+		g.audioContext = audio.NewContext(rate)
+	} else if g.audioContext.SampleRate() != rate {
+		g.logger.Printf("Audio context already running at %d Hz, resampling music", g.audioContext.SampleRate())
+	}
+
+	if musicData != nil {
 		musicReader := bytes.NewReader(musicData)
-		decodedMusic, err := mp3.DecodeWithSampleRate(44100, musicReader)
+		decodedMusic, err := mp3.DecodeWithSampleRate(g.audioContext.SampleRate(), musicReader)
 		if err != nil {
 			return fmt.Errorf("failed to decode music: %v", err)
 		}
@@ -264,14 +1356,260 @@ func (g *Game) Init() error {
 			return fmt.Errorf("failed to create audio player: %v", err)
 		}
 
+		if g.musicFadeIn > 0 {
+			g.audioPlayer.SetVolume(0)
+		}
 		g.audioPlayer.Play()
 	}
 
 	return nil
 }
 
+// Close libère le lecteur audio et les images du jeu. Les appels suivants sont sans effet.
+func (g *Game) Close() error {
+	if g.closed {
+		return nil
+	}
+	g.closed = true
+
+	var err error
+	if g.audioPlayer != nil {
+		g.audioPlayer.Pause()
+		err = g.audioPlayer.Close()
+		g.audioPlayer = nil
+	}
+
+	images := []**ebiten.Image{
+		&g.backdrop, &g.mountains, &g.sphere, &g.logo,
+		&g.chessboard, &g.chessboardMask,
+		&g.scrollCanvas1, &g.scrollCanvas2, &g.scrollCanvas4, &g.scrollCanvas5,
+		&g.vignetteImage, &g.scanlineImage, &g.spotlightImage, &g.offscreen, &g.layerImage, &g.blurTemp, &g.blurImage, &g.photoFrame, &g.pauseFrame, &g.introStrip,
+	}
+	for _, f := range []*Font{g.font1, g.fontIn, g.fontOut} {
+		if f != nil {
+			images = append(images, &f.Image)
+		}
+	}
+	for i := range g.spheres {
+		images = append(images, &g.spheres[i])
+	}
+	for i := range g.shadows {
+		images = append(images, &g.shadows[i])
+	}
+	for code, img := range g.glyphs {
+		img.Dispose()
+		delete(g.glyphs, code)
+	}
+	for _, img := range images {
+		if *img != nil {
+			(*img).Dispose()
+			*img = nil
+		}
+	}
+
+	for _, shader := range []**ebiten.Shader{&g.floorShader, &g.ballShader, &g.invertShader, &g.blurShader} {
+		if *shader != nil {
+			(*shader).Deallocate()
+			*shader = nil
+		}
+	}
+	g.stateHistory = nil
+
+	if g.assetZip != nil {
+		if zerr := g.assetZip.Close(); err == nil {
+			err = zerr
+		}
+		g.assetZip = nil
+	}
+
+	return err
+}
+
+// Octets sentinelles des sprites intégrés, à insérer dans le texte du scroller
+// (par exemple "GREETINGS \x01 TO ALL")
+const (
+	glyphStar  byte = 1
+	glyphHeart byte = 2
+)
+
+// newStarGlyph génère une étoile dorée à cinq branches de size pixels
+func newStarGlyph(size int) *ebiten.Image {
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	c := float64(size) / 2
+
+	// Sommets alternés des branches et des creux, pointe vers le haut
+	var xs, ys [10]float64
+	for k := 0; k < 10; k++ {
+		r := c
+		if k%2 == 1 {
+			r = c * 0.4
+		}
+		a := -math.Pi/2 + float64(k)*math.Pi/5
+		xs[k], ys[k] = c+r*math.Cos(a), c+r*math.Sin(a)
+	}
+
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			px, py := float64(x)+0.5, float64(y)+0.5
+
+			// Test pair-impair du point dans le polygone
+			inside := false
+			for i, j := 0, 9; i < 10; j, i = i, i+1 {
+				if (ys[i] > py) != (ys[j] > py) && px < (xs[j]-xs[i])*(py-ys[i])/(ys[j]-ys[i])+xs[i] {
+					inside = !inside
+				}
+			}
+			if inside {
+				img.SetRGBA(x, y, color.RGBA{255, 210, 40, 255})
+			}
+		}
+	}
+
+	return ebiten.NewImageFromImage(img)
+}
+
+// newHeartGlyph génère un cœur rouge de size pixels
+func newHeartGlyph(size int) *ebiten.Image {
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			// Courbe (x²+y²-1)³ - x²y³ = 0, dans [-1.3, 1.3]
+			u := (float64(x)+0.5)/float64(size)*2.6 - 1.3
+			v := 1.3 - (float64(y)+0.5)/float64(size)*2.6
+			q := u*u + v*v - 1
+			if q*q*q-u*u*v*v*v <= 0 {
+				img.SetRGBA(x, y, color.RGBA{230, 30, 60, 255})
+			}
+		}
+	}
+
+	return ebiten.NewImageFromImage(img)
+}
+
+// RegisterGlyph associe un sprite à un octet sentinelle du texte du scroller
+// principal. Seuls les octets de contrôle (1 à 31) sont acceptés, pour ne pas
+// masquer un caractère de la font.
+func (g *Game) RegisterGlyph(code byte, img *ebiten.Image) error {
+	if code == 0 || code >= 32 {
+		return fmt.Errorf("glyph code must be between 1 and 31, got %d", code)
+	}
+	if img == nil {
+		return fmt.Errorf("glyph %d has no image", code)
+	}
+
+	if g.glyphs == nil {
+		g.glyphs = make(map[byte]*ebiten.Image)
+	}
+	g.glyphs[code] = img
+	return nil
+}
+
+// registerBuiltinGlyphs enregistre les sprites intégrés (étoile, cœur) dont
+// la sentinelle apparaît dans text. Sans sprite enregistré, les scrollers
+// gardent le chemin à largeur fixe.
+func (g *Game) registerBuiltinGlyphs(text string) {
+	for _, b := range []struct {
+		code   byte
+		create func(size int) *ebiten.Image
+	}{
+		{glyphStar, newStarGlyph},
+		{glyphHeart, newHeartGlyph},
+	} {
+		if _, ok := g.glyphs[b.code]; !ok && strings.IndexByte(text, b.code) >= 0 {
+			g.RegisterGlyph(b.code, b.create(40))
+		}
+	}
+}
+
+// glyphWidth retourne l'avance horizontale d'un caractère ou d'un sprite
+func (g *Game) glyphWidth(font *Font, char byte) float64 {
+	if img, ok := g.glyphs[char]; ok {
+		return float64(img.Bounds().Dx())
+	}
+	return float64(font.CellWidth)
+}
+
+// textWidth retourne la largeur d'un texte pouvant contenir des sprites
+func (g *Game) textWidth(font *Font, text string) float64 {
+	if len(g.glyphs) == 0 {
+		return font.measureText(text)
+	}
+
+	var w float64
+	for i := 0; i < len(text); i++ {
+		w += g.glyphWidth(font, text[i])
+	}
+	return w
+}
+
+// fontNames liste les fonts sélectionnables par nom pour les scrollers :
+// "kh6" est la font de l'intro, "out" celle du scroller principal et "in" la
+// planche compagne de "out", découpée avec les mêmes cellules
+var fontNames = []string{"kh6", "in", "out"}
+
+// validFontName indique si name désigne une font connue
+func validFontName(name string) bool {
+	for _, n := range fontNames {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// fontByName retourne la font chargée correspondant à name
+func (g *Game) fontByName(name string) (*Font, error) {
+	var f *Font
+	switch name {
+	case "kh6":
+		f = g.font1
+	case "in":
+		f = g.fontIn
+	case "out":
+		f = g.fontOut
+	default:
+		return nil, fmt.Errorf("unknown font %q, expected one of %v", name, fontNames)
+	}
+	if !fontReady(f) {
+		return nil, fmt.Errorf("font %q is not loaded", name)
+	}
+	return f, nil
+}
+
+// SetIntroFont choisit par nom la font du scroller d'intro (après Init)
+func (g *Game) SetIntroFont(name string) error {
+	f, err := g.fontByName(name)
+	if err != nil {
+		return err
+	}
+	g.introFont = f
+	return nil
+}
+
+// SetMainFont choisit par nom la font du scroller principal (après Init)
+func (g *Game) SetMainFont(name string) error {
+	f, err := g.fontByName(name)
+	if err != nil {
+		return err
+	}
+	g.mainFont = f
+	return nil
+}
+
+// fontReady indique si une font peut servir au dessin ; sinon le scroller
+// revient à sa font par défaut
+func fontReady(f *Font) bool {
+	return f != nil && f.Image != nil
+}
+
 // drawChar dessine un caractère de la font
-func (g *Game) drawChar(dst *ebiten.Image, font *ebiten.Image, char byte, x, y float64, scale float64) {
+func (g *Game) drawChar(dst *ebiten.Image, font *Font, char byte, x, y float64, scale float64) {
+	g.drawCharTinted(dst, font, char, x, y, scale, ebiten.ColorScale{})
+}
+
+// drawCharTinted dessine un caractère de la font en multipliant ses couleurs par tint
+func (g *Game) drawCharTinted(dst *ebiten.Image, font *Font, char byte, x, y float64, scale float64, tint ebiten.ColorScale) {
 	index := 0
 
 	switch char {
@@ -373,142 +1711,350 @@ func (g *Game) drawChar(dst *ebiten.Image, font *ebiten.Image, char byte, x, y f
 		index = 0
 	}
 
-	cols := 10
-	srcX := (index % cols) * fontWidth
-	srcY := (index / cols) * fontHeight
+	srcX := (index % font.Cols) * font.CellWidth
+	srcY := (index / font.Cols) * font.CellHeight
 
 	op := &ebiten.DrawImageOptions{}
 	op.GeoM.Scale(scale, scale)
 	op.GeoM.Translate(x, y)
+	op.ColorScale = tint
 
-	charImg := font.SubImage(image.Rect(srcX, srcY, srcX+fontWidth, srcY+fontHeight)).(*ebiten.Image)
+	charImg := font.Image.SubImage(image.Rect(srcX, srcY, srcX+font.CellWidth, srcY+font.CellHeight)).(*ebiten.Image)
 	dst.DrawImage(charImg, op)
 }
 
-// drawScrollText dessine un texte défilant
-func (g *Game) drawScrollText(dst *ebiten.Image, font *ebiten.Image, text string, scrollX float64) float64 {
-	charSpacing := float64(fontWidth)
-	startChar := int(scrollX / charSpacing)
-	offset := math.Mod(scrollX, charSpacing)
-
-	// Calculer combien de caractères on peut afficher sur toute la largeur
-	maxChars := int(float64(dst.Bounds().Dx())/charSpacing) + 3
+// drawScrollText dessine un texte défilant, décalé de scrollX pixels. La
+// position est avancée par Update avec advanceScroll.
+func (g *Game) drawScrollText(dst *ebiten.Image, font *Font, text string, scrollX float64) {
+	charSpacing := float64(font.CellWidth)
+	startChar, offset := g.scrollStart(font, text, scrollX)
 
-	for i := 0; i < maxChars; i++ {
-		charIndex := (startChar + i) % len(text)
+	drawGlyphs := func(dx, dy float64, tint ebiten.ColorScale) {
+		charIndex := startChar % len(text)
 		if charIndex < 0 {
 			charIndex += len(text)
 		}
 
-		x := float64(i)*charSpacing - offset
-		if x >= -charSpacing && x < float64(dst.Bounds().Dx())+charSpacing {
-			g.drawChar(dst, font, text[charIndex], x, 0, 1)
+		for x := -offset; x < float64(dst.Bounds().Dx())+charSpacing; {
+			char := text[charIndex]
+			if img, ok := g.glyphs[char]; ok {
+				// Sprite centré verticalement dans la hauteur d'une cellule
+				op := &ebiten.DrawImageOptions{}
+				op.GeoM.Translate(x+dx, dy+float64(font.CellHeight-img.Bounds().Dy())/2)
+				op.ColorScale = tint
+				dst.DrawImage(img, op)
+			} else {
+				g.drawCharTinted(dst, font, char, x+dx, dy, 1, tint)
+			}
+
+			x += g.glyphWidth(font, char)
+			charIndex = (charIndex + 1) % len(text)
+		}
+	}
+
+	// Ombre portée : toutes les ombres d'abord pour qu'aucune ne recouvre un caractère
+	if g.scrollShadow {
+		var shadow ebiten.ColorScale
+		shadow.ScaleWithColor(g.scrollShadowColor)
+		drawGlyphs(g.scrollShadowOffset, g.scrollShadowOffset, shadow)
+	}
+
+	// Contour : le caractère teinté décalé dans les 8 directions, sous le normal
+	if g.scrollOutline {
+		var outline ebiten.ColorScale
+		outline.ScaleWithColor(g.scrollOutlineColor)
+		w := g.scrollOutlineWidth
+		for _, d := range [][2]float64{{-1, -1}, {0, -1}, {1, -1}, {-1, 0}, {1, 0}, {-1, 1}, {0, 1}, {1, 1}} {
+			drawGlyphs(d[0]*w, d[1]*w, outline)
+		}
+	}
+	drawGlyphs(0, 0, ebiten.ColorScale{})
+}
+
+// drawText dessine un texte fixe à partir de (x, y). Les caractères entièrement
+// hors de dst sont ignorés, ceux à cheval sur un bord sont découpés par dst.
+func (g *Game) drawText(dst *ebiten.Image, font *Font, text string, x, y, scale float64) {
+	charSpacing := float64(font.CellWidth) * scale
+	charHeight := float64(font.CellHeight) * scale
+	bounds := dst.Bounds()
+
+	if y+charHeight <= float64(bounds.Min.Y) || y >= float64(bounds.Max.Y) {
+		return
+	}
+
+	for i := 0; i < len(text); i++ {
+		cx := x + float64(i)*charSpacing
+		if cx+charSpacing <= float64(bounds.Min.X) {
+			continue
+		}
+		if cx >= float64(bounds.Max.X) {
+			break
+		}
+		g.drawChar(dst, font, text[i], cx, y, scale)
+	}
+}
+
+// scrollPosition retourne le premier caractère visible et le décalage en pixels
+// dans ce caractère, y compris pour une position négative
+func scrollPosition(scrollX, charSpacing float64) (int, float64) {
+	first := math.Floor(scrollX / charSpacing)
+	return int(first), scrollX - first*charSpacing
+}
+
+// scrollStart retourne le premier caractère visible d'un texte et le décalage
+// dans ce caractère. Sans sprite, toutes les cellules ont la même largeur ;
+// sinon on cumule les largeurs jusqu'à scrollX.
+func (g *Game) scrollStart(font *Font, text string, scrollX float64) (int, float64) {
+	if len(g.glyphs) == 0 {
+		return scrollPosition(scrollX, float64(font.CellWidth))
+	}
+
+	length := g.textWidth(font, text)
+	pos := math.Floor(scrollX/length) * length
+	start := 0
+	for pos+g.glyphWidth(font, text[start]) <= scrollX {
+		pos += g.glyphWidth(font, text[start])
+		start = (start + 1) % len(text)
+	}
+	return start, scrollX - pos
+}
+
+// advanceScroll fait avancer la position du texte en la gardant dans [0, length)
+func advanceScroll(scrollX, speed, length float64) float64 {
+	scrollX = math.Mod(scrollX+speed, length)
+	if scrollX < 0 {
+		scrollX += length
+	}
+	return scrollX
+}
+
+// drawCachedScrollText fonctionne comme drawScrollText mais garde les caractères
+// visibles dans une bande pré-rendue, redessinée seulement quand un nouveau
+// caractère entre à l'écran. Chaque image ne fait que décaler la bande.
+func (g *Game) drawCachedScrollText(dst *ebiten.Image, font *Font, text string, scrollX float64) {
+	charSpacing := float64(font.CellWidth)
+	startChar, offset := g.scrollStart(font, text, scrollX)
+	startChar %= len(text)
+	if startChar < 0 {
+		startChar += len(text)
+	}
+	maxChars := int(float64(dst.Bounds().Dx())/charSpacing) + 3
+
+	// L'ombre et le contour débordent sous la cellule : la bande est plus haute
+	margin := 0.0
+	if g.scrollShadow {
+		margin = math.Max(margin, g.scrollShadowOffset)
+	}
+	if g.scrollOutline {
+		margin = math.Max(margin, g.scrollOutlineWidth)
+	}
+
+	stripWidth := int(float64(maxChars) * charSpacing)
+	stripHeight := font.CellHeight + int(math.Ceil(margin))
+	if g.introStrip == nil || g.introStrip.Bounds().Dx() != stripWidth || g.introStrip.Bounds().Dy() != stripHeight {
+		if g.introStrip != nil {
+			g.introStrip.Dispose()
+		}
+		g.introStrip = ebiten.NewImage(stripWidth, stripHeight)
+		g.introStripChar = -1
+	}
+
+	if startChar != g.introStripChar || text != g.introStripText || font != g.introStripFont ||
+		g.scrollShadow != g.introStripShadow || g.scrollOutline != g.introStripOutline {
+		g.introStrip.Clear()
+		g.drawScrollText(g.introStrip, font, text, scrollX-offset)
+		g.introStripChar = startChar
+		g.introStripText = text
+		g.introStripFont = font
+		g.introStripShadow = g.scrollShadow
+		g.introStripOutline = g.scrollOutline
+	}
+
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(-offset, 0)
+	dst.DrawImage(g.introStrip, op)
+}
+
+// drawRevealScrollText dessine le texte d'intro avec fontIn et fontOut
+// superposées : une lettre qui entre par la droite est tirée de fontIn, puis
+// passe progressivement à fontOut en approchant du centre de l'écran. Les
+// sprites sont dessinés tels quels.
+func (g *Game) drawRevealScrollText(dst *ebiten.Image, text string, scrollX float64) {
+	charSpacing := float64(g.fontOut.CellWidth)
+	startChar, offset := g.scrollStart(g.fontOut, text, scrollX)
+	charIndex := startChar % len(text)
+	if charIndex < 0 {
+		charIndex += len(text)
+	}
+
+	center := float64(g.width) / 2
+	for x := -offset; x < float64(dst.Bounds().Dx())+charSpacing; {
+		char := text[charIndex]
+		if img, ok := g.glyphs[char]; ok {
+			op := &ebiten.DrawImageOptions{}
+			op.GeoM.Translate(x, float64(g.fontOut.CellHeight-img.Bounds().Dy())/2)
+			dst.DrawImage(img, op)
+		} else {
+			// 0 au bord droit, 1 à partir du centre
+			reveal := math.Min(1, math.Max(0, (float64(g.width)-(x+charSpacing/2))/center))
+
+			var in, out ebiten.ColorScale
+			in.ScaleAlpha(float32(1 - reveal))
+			out.ScaleAlpha(float32(reveal))
+			g.drawCharTinted(dst, g.fontIn, char, x, 0, 1, in)
+			g.drawCharTinted(dst, g.fontOut, char, x, 0, 1, out)
 		}
+
+		x += g.glyphWidth(g.fontOut, char)
+		charIndex = (charIndex + 1) % len(text)
+	}
+}
+
+// EnqueueScrollText ajoute des messages joués l'un après l'autre par le
+// scroller principal, à la place du texte en boucle. À l'envers, chaque
+// message entre par la gauche et sort par la droite.
+func (g *Game) EnqueueScrollText(msgs ...string) {
+	for _, msg := range msgs {
+		g.registerBuiltinGlyphs(msg)
+	}
+
+	start := len(g.scrollQueue)
+	g.scrollQueue = append(g.scrollQueue, msgs...)
+
+	// Aucun message en cours (file vide ou terminée) : jouer le premier ajouté
+	if g.scrollQueueIndex < 0 && start < len(g.scrollQueue) {
+		g.startQueuedMessage(start)
+	}
+}
+
+// startQueuedMessage démarre le message index de la file depuis le bord
+// d'entrée du scroller
+func (g *Game) startQueuedMessage(index int) {
+	g.layoutQueuedMessage(index)
+	g.scrollX2 = 0
+	if g.scrollReverse {
+		g.scrollX2 = g.scrollQueueEnd
+	}
+}
+
+// layoutQueuedMessage prépare le texte du message index de la file. Il est
+// entouré d'espaces sur toute la largeur du canvas pour entrer par la droite
+// et sortir entièrement par la gauche avant le suivant.
+func (g *Game) layoutQueuedMessage(index int) {
+	cellWidth := fontWidth
+	if g.mainFont != nil {
+		cellWidth = g.mainFont.CellWidth
+	}
+
+	pad := strings.Repeat(" ", g.scrollCanvasWidth()/cellWidth+1)
+	padWidth := float64(len(pad) * cellWidth)
+	g.scrollQueueIndex = index
+	g.text2 = pad + g.scrollQueue[index] + pad
+	g.scrollQueueEnd = padWidth + g.scrollQueueTextWidth(g.scrollQueue[index])
+
+	// À l'envers, le texte part de la marge de fin et le message est sorti
+	// quand la partie visible ne montre plus que la marge de début
+	g.scrollQueueReverseEnd = padWidth - float64(g.scrollCanvasWidth())
+}
+
+// scrollQueueTextWidth mesure un message de la file, sprites compris
+func (g *Game) scrollQueueTextWidth(text string) float64 {
+	if g.mainFont == nil {
+		return float64(len(text) * fontWidth)
+	}
+	return g.textWidth(g.mainFont, text)
+}
+
+// advanceScrollQueue passe au message suivant quand le courant a quitté l'écran
+func (g *Game) advanceScrollQueue() {
+	if len(g.scrollQueue) == 0 || g.scrollQueueIndex < 0 {
+		return
+	}
+	if g.scrollReverse && g.scrollX2 > g.scrollQueueReverseEnd {
+		return
+	}
+	if !g.scrollReverse && g.scrollX2 < g.scrollQueueEnd {
+		return
 	}
 
-	// Vitesse de défilement
-	return math.Mod(scrollX+3, float64(len(text))*charSpacing)
+	next := g.scrollQueueIndex + 1
+	if next >= len(g.scrollQueue) {
+		if !g.scrollQueueLoop {
+			// File terminée : le scroller reste vide
+			g.scrollQueueIndex = -1
+			g.text2 = " "
+			g.scrollX2 = 0
+			return
+		}
+		next = 0
+	}
+	g.startQueuedMessage(next)
 }
 
 // drawScroller dessine le scroller avec effets
 func (g *Game) drawScroller(screen *ebiten.Image) {
+	if !fontReady(g.mainFont) {
+		g.mainFont = g.fontOut
+	}
+
 	// Clear canvases
 	g.scrollCanvas2.Clear()
-	g.scrollCanvas3.Clear()
 	g.scrollCanvas5.Clear()
 
 	// Dessiner le texte sur le canvas élargi
-	g.scrollX2 = g.drawScrollText(g.scrollCanvas2, g.fontOut, g.text2, g.scrollX2)
-
-	// Effet de vague sur le scroller
-	for j := 0; j < 25; j++ {
-		srcRect := image.Rect(0, j*2, 1024, (j+1)*2)
-		dstX := g.scrollX[(g.vbl3+j)%g.scrollXMod]
-
-		op := &ebiten.DrawImageOptions{}
-		op.GeoM.Translate(dstX, float64(j*2))
-		g.scrollCanvas3.DrawImage(g.scrollCanvas2.SubImage(srcRect).(*ebiten.Image), op)
-	}
+	g.drawScrollText(g.scrollCanvas2, g.mainFont, g.text2, g.scrollX2)
 
 	// Effet de rebond vertical
 	// yOffset varie de 0 à 60 (30 + 30*cos)
-	yOffset := 30 + 30*math.Cos(g.vbl4/20)
+	motion := g.motionScale()
+	yOffset := 30 + 30*math.Cos(g.vbl4/20)*motion
 
-	// On dessine le scroller avec un décalage vertical
+	// Effet de vague et rebond en une seule passe : appliquer le décalage total
+	// (2*dstX) d'un coup évite un double arrondi au pixel qui fait trembler le texte
 	for j := 0; j < 25; j++ {
-		srcRect := image.Rect(0, j*2, 1024, (j+1)*2)
-		dstX := g.scrollX[(g.vbl3+j)%g.scrollXMod]
+		srcRect := image.Rect(0, j*2, g.scrollWidth, (j+1)*2)
+		dstX := g.scrollX[(g.vbl3+j)%g.scrollXMod] * motion
 
 		// Position verticale avec l'effet de rebond
 		dstY := float64(j*2) + yOffset
 
 		op := &ebiten.DrawImageOptions{}
-		op.GeoM.Translate(dstX, dstY)
-		g.scrollCanvas5.DrawImage(g.scrollCanvas3.SubImage(srcRect).(*ebiten.Image), op)
+		op.GeoM.Translate(2*dstX, dstY)
+		g.scrollCanvas5.DrawImage(g.scrollCanvas2.SubImage(srcRect).(*ebiten.Image), op)
 	}
 
-	// Extraire la partie visible centrée et dessiner directement
-	offsetX := (1024 - 768) / 2
-	visibleRect := image.Rect(offsetX, 0, offsetX+768, 120)
+	// Extraire la partie visible centrée : le découpage reste aligné sur les
+	// pixels, la partie fractionnaire du centrage est appliquée au dessin
+	offsetX := float64(g.scrollWidth-g.width) / 2
+	cropX := int(math.Floor(offsetX))
+	visibleRect := image.Rect(cropX, 0, cropX+g.width+1, 120)
+
+	// Mode contraste élevé : bande unie derrière les caractères, qui suit le rebond
+	if g.highContrast {
+		y := g.mainScrollY*g.layoutScaleY() + yOffset
+		h := float64(g.mainFont.CellHeight)
+		vector.DrawFilledRect(screen, 0, float32(y), float32(g.width), float32(h), g.highContrastColor, false)
+	}
 
 	// Dessiner le résultat final directement sur l'écran
 	op := &ebiten.DrawImageOptions{}
-	op.GeoM.Translate(0, 62)
+	op.GeoM.Translate(float64(cropX)-offsetX, g.mainScrollY*g.layoutScaleY())
+	op.ColorScale.ScaleWithColor(g.scrollTint)
+	if g.highContrast {
+		op.ColorScale.Scale(highContrastBoost, highContrastBoost, highContrastBoost, 1)
+	}
 	screen.DrawImage(g.scrollCanvas5.SubImage(visibleRect).(*ebiten.Image), op)
-
-	g.vbl4 += 1.2
-	g.vbl3++
 }
 
-// drawQuad dessine un quadrilatère rempli
-func drawQuad(img *ebiten.Image, x1, y1, x2, y2, x3, y3, x4, y4 float64, c color.Color) {
-	vertices := []ebiten.Vertex{
-		{
-			DstX:   float32(x1),
-			DstY:   float32(y1),
-			SrcX:   0,
-			SrcY:   0,
-			ColorR: float32(c.(color.RGBA).R) / 255,
-			ColorG: float32(c.(color.RGBA).G) / 255,
-			ColorB: float32(c.(color.RGBA).B) / 255,
-			ColorA: float32(c.(color.RGBA).A) / 255,
-		},
-		{
-			DstX:   float32(x2),
-			DstY:   float32(y2),
-			SrcX:   0,
-			SrcY:   0,
-			ColorR: float32(c.(color.RGBA).R) / 255,
-			ColorG: float32(c.(color.RGBA).G) / 255,
-			ColorB: float32(c.(color.RGBA).B) / 255,
-			ColorA: float32(c.(color.RGBA).A) / 255,
-		},
-		{
-			DstX:   float32(x3),
-			DstY:   float32(y3),
-			SrcX:   0,
-			SrcY:   0,
-			ColorR: float32(c.(color.RGBA).R) / 255,
-			ColorG: float32(c.(color.RGBA).G) / 255,
-			ColorB: float32(c.(color.RGBA).B) / 255,
-			ColorA: float32(c.(color.RGBA).A) / 255,
-		},
-		{
-			DstX:   float32(x4),
-			DstY:   float32(y4),
-			SrcX:   0,
-			SrcY:   0,
-			ColorR: float32(c.(color.RGBA).R) / 255,
-			ColorG: float32(c.(color.RGBA).G) / 255,
-			ColorB: float32(c.(color.RGBA).B) / 255,
-			ColorA: float32(c.(color.RGBA).A) / 255,
-		},
-	}
-
-	indices := []uint16{0, 1, 2, 2, 3, 0}
+// drawQuad dessine un quadrilatère rempli. Les deux premiers sommets prennent
+// la couleur c1 et les deux derniers c2, avec un dégradé entre les deux.
+// rule détermine le rendu des zones couvertes par les deux triangles
+// (quadrilatère croisé).
+func drawQuad(img *ebiten.Image, x1, y1, x2, y2, x3, y3, x4, y4 float64, c1, c2 color.RGBA, rule ebiten.FillRule) {
+	vertices, indices := quadVertices(x1, y1, x2, y2, x3, y3, x4, y4, c1, c2)
 
 	op := &ebiten.DrawTrianglesOptions{}
-	op.FillRule = ebiten.FillAll
+	op.FillRule = rule
 
 	white := ebiten.NewImage(1, 1)
 	white.Fill(color.White)
@@ -516,35 +2062,82 @@ func drawQuad(img *ebiten.Image, x1, y1, x2, y2, x3, y3, x4, y4 float64, c color
 	img.DrawTriangles(vertices, indices, white, op)
 }
 
+// quadVertices retourne les sommets et les deux triangles de drawQuad
+func quadVertices(x1, y1, x2, y2, x3, y3, x4, y4 float64, c1, c2 color.RGBA) ([]ebiten.Vertex, []uint16) {
+	vertex := func(x, y float64, c color.RGBA) ebiten.Vertex {
+		return ebiten.Vertex{
+			DstX:   float32(x),
+			DstY:   float32(y),
+			SrcX:   0,
+			SrcY:   0,
+			ColorR: float32(c.R) / 255,
+			ColorG: float32(c.G) / 255,
+			ColorB: float32(c.B) / 255,
+			ColorA: float32(c.A) / 255,
+		}
+	}
+	vertices := []ebiten.Vertex{
+		vertex(x1, y1, c1),
+		vertex(x2, y2, c1),
+		vertex(x3, y3, c2),
+		vertex(x4, y4, c2),
+	}
+
+	return vertices, []uint16{0, 1, 2, 2, 3, 0}
+}
+
+// lerpRGBA interpole linéairement deux couleurs (t = 0 : a, t = 1 : b)
+func lerpRGBA(a, b color.RGBA, t float64) color.RGBA {
+	l := func(x, y uint8) uint8 {
+		return uint8(math.Round(float64(x) + (float64(y)-float64(x))*t))
+	}
+	return color.RGBA{l(a.R, b.R), l(a.G, b.G), l(a.B, b.B), l(a.A, b.A)}
+}
+
 // drawChessboard dessine le damier avec perspective
 func (g *Game) drawChessboard() {
 	g.chessboard.Clear()
 
-	g.xMove += g.xm * g.speed * 0.005
-	if g.xMove > 32 {
-		g.xMove -= 32
-	}
-	if g.xMove < 0 {
-		g.xMove += 32
+	near, far := g.floorNearColor, g.floorFarColor
+
+	// Variante procédurale : tout le damier en un seul appel au shader
+	if g.useShaderFloor && g.floorShader != nil {
+		bounds := g.chessboard.Bounds()
+		op := &ebiten.DrawRectShaderOptions{}
+		op.Uniforms = map[string]any{
+			"XMove":  float32(g.xMove),
+			"YMove":  float32(g.yMove),
+			"Fov":    float32(g.fov),
+			"Height": float32(bounds.Dy()),
+			"Color": []float32{
+				float32(near.R) / 255,
+				float32(near.G) / 255,
+				float32(near.B) / 255,
+				float32(near.A) / 255,
+			},
+			"FarColor": []float32{
+				float32(far.R) / 255,
+				float32(far.G) / 255,
+				float32(far.B) / 255,
+				float32(far.A) / 255,
+			},
+		}
+		g.chessboard.DrawRectShader(bounds.Dx(), bounds.Dy(), g.floorShader, op)
+		return
 	}
 
-	chessColor := color.RGBA{96, 96, 96, 255}
-
 	for i := -5; i < 50; i++ {
 		x1 := -8 + float64(i)*32 + g.xMove
 		x2 := 8 + float64(i)*32 + g.xMove
 		x3 := -752 + float64(i)*192 + g.xMove*6
 		x4 := -848 + float64(i)*192 + g.xMove*6
 
-		drawQuad(g.chessboard, x1, 0, x2, 0, x3, 80, x4, 80, chessColor)
+		// Le haut du damier est le plus lointain
+		drawQuad(g.chessboard, x1, 0, x2, 0, x3, 80, x4, 80, far, near, g.floorFillRule)
 	}
 
-	g.yMove += g.ym * g.speed * 0.016
-	if g.yMove > 64 {
-		g.yMove -= 64
-	}
-	if g.yMove < 0 {
-		g.yMove += 64
+	if !g.floorCheckered {
+		return
 	}
 
 	g.chessboardMask.Clear()
@@ -561,25 +2154,29 @@ func (g *Game) drawChessboard() {
 			startY := math.Max(0, y1)
 			endY := math.Min(80, y2)
 
-			vector.DrawFilledRect(g.chessboardMask, 0, float32(startY), 1280, float32(endY-startY), chessColor, false)
+			// Même dégradé que les bandes, pris au milieu de la rangée
+			c := lerpRGBA(far, near, (startY+endY)/2/80)
+			vector.DrawFilledRect(g.chessboardMask, 0, float32(startY), 1280, float32(endY-startY), c, false)
 		}
 	}
 
+	g.chessboard.DrawImage(g.chessboardMask, g.floorMaskOptions())
+}
+
+// floorMaskOptions retourne les options de composition du masque des rangées
+// sur les bandes du damier, selon floorCompositeMode
+func (g *Game) floorMaskOptions() *ebiten.DrawImageOptions {
 	op := &ebiten.DrawImageOptions{}
-	op.CompositeMode = ebiten.CompositeModeXor
-	g.chessboard.DrawImage(g.chessboardMask, op)
+	op.CompositeMode = g.floorCompositeMode
+	return op
 }
 
 // getMovement retourne les paramètres d'animation selon l'index
 func getMovement(index int, t float64, i int) Anim {
-	// Toujours éviter les animations 0 et 1 après le début
-	if index < 2 && t > 21 { // Après 3 cycles de 7 secondes
-		index = 2 + int(t/7)%6 // Boucler sur les animations 2-7
-	}
-
 	switch index {
 	case 0, 1:
-		return Anim{-5, 40, 0, 0}
+		// Vague d'attente : rotation lente et léger balancement
+		return Anim{-3, math.Sin((t+float64(i))*1.5)*15 - 20, 90, 120}
 	case 2:
 		return Anim{-5, -60 - math.Sin(t*7)*95, 35, 150}
 	case 3:
@@ -593,97 +2190,304 @@ func getMovement(index int, t float64, i int) Anim {
 	case 7:
 		return Anim{-8, 10 - math.Abs(math.Sin((t*0.6+float64(i)*0.05)*1.75)*70)*2.3, 20, 150}
 	default:
-		// Pour les indices > 7, boucler sur les mouvements 2-7
-		return getMovement(2+(index-2)%6, t, i)
+		// Pour les indices > 7, boucler sur les mouvements 0-7
+		return getMovement(index%8, t, i)
 	}
 }
 
-// blendAnim mélange deux animations
-func blendAnim(a, b Anim, alpha float64) Anim {
-	return Anim{
-		SpinSpeed:                a.SpinSpeed*(1-alpha) + b.SpinSpeed*alpha,
-		Displace:                 a.Displace*(1-alpha) + b.Displace*alpha,
-		BallLineDisplacement:     a.BallLineDisplacement*(1-alpha) + b.BallLineDisplacement*alpha,
-		RadiusFromCenterOfScreen: a.RadiusFromCenterOfScreen*(1-alpha) + b.RadiusFromCenterOfScreen*alpha,
-	}
+// animSegment décrit la forme d'onde jouée à un instant de la chorégraphie
+type animSegment struct {
+	Index    int     // Forme d'onde courante
+	Next     int     // Forme d'onde du segment suivant
+	Elapsed  float64 // Temps écoulé dans le segment, en secondes
+	Duration float64 // Durée du segment, en secondes
 }
 
-// drawDoc dessine les sphères 3D animées
-func (g *Game) drawDoc(screen *ebiten.Image) {
-	const (
-		FOCAL_LENGTH  = 400
-		BALL_WIDTH    = 64
-		BALL_HEIGHT   = 64
-		SHADOW_WIDTH  = 64
-		SHADOW_HEIGHT = 16
-		ANIM_DURATION = 7
-	)
+// segmentAt retourne le segment joué au temps t, en cumulant les durées de
+// chaque forme d'onde. Les deux premiers segments jouent l'animation 7, comme
+// à l'origine ; la suite boucle sur 2, 3, ..., 7, 0, 1.
+func segmentAt(t float64, durations []float64) animSegment {
+	d := func(index int) float64 { return durations[index%len(durations)] }
+
+	// Le premier cycle démarre sur l'animation 7
+	for s := 0; s < 2; s++ {
+		if t < d(7) {
+			next := 7
+			if s == 1 {
+				next = 2
+			}
+			return animSegment{Index: 7, Next: next, Elapsed: t, Duration: d(7)}
+		}
+		t -= d(7)
+	}
 
-	t := time.Since(g.startTime).Seconds()
+	var total float64
+	for _, v := range durations {
+		total += v
+	}
+	t = math.Mod(t, total)
 
-	// Gestion de la boucle d'animation
-	if g.overWriteFirstTwoWaveforms && t > ANIM_DURATION*3 {
-		g.overWriteFirstTwoWaveforms = false
+	for k := 0; k < len(durations); k++ {
+		index := (2 + k) % len(durations)
+		if t < d(index) {
+			return animSegment{Index: index, Next: (index + 1) % len(durations), Elapsed: t, Duration: d(index)}
+		}
+		t -= d(index)
 	}
 
-	balls := make([]Sprite, 4)
-	ballShadows := make([]Sprite, 4)
+	// Arrondi en fin de cycle : rester sur le dernier segment
+	return animSegment{Index: 1, Next: 2, Elapsed: d(1), Duration: d(1)}
+}
 
-	for i := 0; i < 4; i++ {
-		// Déterminer l'index d'animation actuel
-		animIndex := int(t/ANIM_DURATION) % 8 // Changé de 7 à 8 pour inclure plus de variations
+// SetAnimDurations règle la durée en secondes de chacune des 8 formes d'onde
+func (g *Game) SetAnimDurations(durations []float64) error {
+	if len(durations) != 8 {
+		return fmt.Errorf("expected 8 durations, got %d", len(durations))
+	}
 
-		// Après les 3 premières boucles, éviter les animations 0 et 1
-		if !g.overWriteFirstTwoWaveforms && animIndex < 2 {
-			animIndex = 2 + int(t/ANIM_DURATION)%6
+	var total float64
+	for _, v := range durations {
+		if v < 0 {
+			return fmt.Errorf("durations must not be negative, got %v", v)
 		}
+		total += v
+	}
+	if total <= 0 || durations[7] <= 0 {
+		return fmt.Errorf("durations must have a positive total and a positive duration for waveform 7")
+	}
+
+	g.animDurations = append([]float64(nil), durations...)
+	return nil
+}
+
+// blendAlpha retourne la progression du blend après elapsed secondes dans un
+// segment de segmentDuration secondes, pour une transition durant blendDuration secondes
+func blendAlpha(elapsed, blendDuration, segmentDuration float64) float64 {
+	blendDuration = math.Min(blendDuration, segmentDuration)
+	if blendDuration <= 0 {
+		return 1
+	}
+	return math.Min(1, elapsed/blendDuration)
+}
 
-		// Si on est dans les 3 premières boucles et sur les animations 0 ou 1,
-		// forcer l'utilisation de l'animation 7
-		if g.overWriteFirstTwoWaveforms && animIndex < 2 {
-			animIndex = 7
+// blendAnim mélange deux animations
+func blendAnim(a, b Anim, alpha float64) Anim {
+	return Anim{
+		SpinSpeed:                a.SpinSpeed*(1-alpha) + b.SpinSpeed*alpha,
+		Displace:                 a.Displace*(1-alpha) + b.Displace*alpha,
+		BallLineDisplacement:     a.BallLineDisplacement*(1-alpha) + b.BallLineDisplacement*alpha,
+		RadiusFromCenterOfScreen: a.RadiusFromCenterOfScreen*(1-alpha) + b.RadiusFromCenterOfScreen*alpha,
+	}
+}
+
+// ringPosition calcule la position 3D d'une boule de l'anneau, dont le plan
+// est incliné de tilt radians autour de l'axe X
+func ringPosition(anim Anim, i int, radians, tilt float64) Vec3 {
+	// Créer la position de base sur le cercle
+	currentPos := Vec3{X: anim.RadiusFromCenterOfScreen, Y: 0, Z: 0}
+	currentPos.RotateY(math.Pi * 2 / 360 * anim.BallLineDisplacement * float64(i))
+
+	// Ajouter le déplacement vertical
+	d := Vec3{X: 0, Y: anim.Displace, Z: 0}
+	p := Vec3{X: currentPos.X + d.X, Y: currentPos.Y + d.Y, Z: currentPos.Z + d.Z}
+
+	p.RotateY(radians)
+	if tilt != 0 {
+		p.RotateX(tilt)
+	}
+	return p
+}
+
+// accumulateRadians fait avancer la rotation de l'anneau selon SpinSpeed
+func accumulateRadians(radians, spinSpeed float64) float64 {
+	// Réduire la vitesse de rotation pour plus de fluidité
+	radians += (math.Pi * 2 / 360) * spinSpeed * 0.15 // Changé de 0.2 à 0.15
+
+	// math.Mod garde le signe : ramener les rotations négatives dans [0, 2π)
+	radians = math.Mod(radians, math.Pi*2)
+	if radians < 0 {
+		radians += math.Pi * 2
+		// Un reste infime (-1e-17) arrondi donne exactement 2π
+		if radians >= math.Pi*2 {
+			radians = 0
 		}
+	}
+	return radians
+}
 
-		// Calculer l'alpha pour le blend entre deux animations
-		// Réduire la vitesse de transition pour plus de fluidité
-		alpha := math.Min(1, math.Mod(t/ANIM_DURATION, 1)*ANIM_DURATION*0.8) // Changé de 1.3 à 0.8
+// continuousShadowAlpha retourne l'opacité d'une ombre selon son échelle
+// projetée : pleine au premier plan (W >= 1.1, le niveau le plus net), elle
+// décroît jusqu'à 0.35 pour W <= 0.5, la plage couverte par les 4 niveaux
+func continuousShadowAlpha(w float64) float64 {
+	a := math.Max(0, math.Min(1, (w-0.5)/0.6))
+	return 0.35 + 0.65*a
+}
 
-		// Obtenir les deux mouvements à mélanger
-		a := getMovement(animIndex, t, i)
-		b := getMovement(animIndex+1, t, i)
-		anim := blendAnim(a, b, alpha)
+// shadowLiftAt retourne le décalage vertical d'une ombre selon son échelle
+// projetée : nul pour W >= 1, il atteint lift pour W <= 0. Un exposant
+// supérieur à 1 retarde la montée, inférieur à 1 l'accélère.
+func shadowLiftAt(w, lift, curve float64) float64 {
+	t := math.Min(1, math.Max(0, 1-w))
+	if curve > 0 && curve != 1 {
+		t = math.Pow(t, curve)
+	}
+	return t * lift
+}
+
+// smoothSpin applique une moyenne mobile exponentielle à la vitesse de
+// rotation : smoothing = 0 suit target immédiatement, plus il approche de 1,
+// plus les changements de sens sont progressifs
+func smoothSpin(prev, target, smoothing float64) float64 {
+	smoothing = math.Max(0, math.Min(0.999, smoothing))
+	return prev*smoothing + target*(1-smoothing)
+}
+
+// fogFactor retourne l'atténuation de couleur d'une sphère selon son échelle
+// projetée w. Le brouillard est nul au plan Z=0 et complet à Z=FocalLength :
+// ces deux bornes sont les W de la caméra, le résultat ne dépend donc pas de
+// SpriteScale.
+func (c Camera) fogFactor(w, strength float64) float32 {
+	near := c.Scale(0) * c.SpriteScale
+	far := c.Scale(c.FocalLength) * c.SpriteScale
+	if near <= far {
+		return 1
+	}
+
+	depth := math.Max(0, math.Min(1, (near-w)/(near-far)))
+	return float32(math.Max(0, 1-depth*strength))
+}
 
-		// Créer la position de base sur le cercle
-		currentPos := Vec3{X: anim.RadiusFromCenterOfScreen, Y: 0, Z: 0}
-		currentPos.RotateY(math.Pi * 2 / 360 * anim.BallLineDisplacement * float64(i))
+// spriteHalfSize retourne la demi-largeur et la demi-hauteur réelles d'un sprite
+func spriteHalfSize(img *ebiten.Image) (float64, float64) {
+	b := img.Bounds()
+	return float64(b.Dx()) * 0.5, float64(b.Dy()) * 0.5
+}
 
-		// Ajouter le déplacement vertical
-		d := Vec3{X: 0, Y: anim.Displace, Z: 0}
-		p := Vec3{X: currentPos.X + d.X, Y: currentPos.Y + d.Y, Z: currentPos.Z + d.Z}
+// depthKey arrondit une profondeur au multiple de depthQuantum le plus proche
+func depthKey(z float64) float64 {
+	return math.Round(z / depthQuantum)
+}
+
+// drawOrder retourne les indices des boules à dessiner, triés par profondeur Z
+// (plus loin en premier), en écartant celles situées devant le plan proche.
+// Les indices maintiennent la correspondance boule/ombre. Les profondeurs sont
+// arrondies à depthQuantum avant comparaison : deux boules presque à la même
+// profondeur gardent l'ordre de leurs indices (tri stable), pour que l'ordre
+// de dessin ne change pas d'une image à l'autre. Arrondir plutôt que comparer
+// avec une tolérance garde une relation d'ordre transitive.
+func (g *Game) drawOrder(balls []Sprite) []int {
+	indices := make([]int, 0, len(balls))
+	for i, b := range balls {
+		if g.camera.Visible(b.Z) {
+			indices = append(indices, i)
+		}
+	}
+
+	sort.SliceStable(indices, func(a, b int) bool {
+		return depthKey(balls[indices[a]].Z) > depthKey(balls[indices[b]].Z)
+	})
+
+	return indices
+}
+
+// drawDoc dessine les sphères 3D animées
+func (g *Game) drawDoc(screen *ebiten.Image) {
+	t := g.animTime
+
+	// La caméra suit une fraction du balancement du damier ; boules et ombres
+	// sont projetées avec la même caméra et restent donc alignées
+	cam := g.camera
+	cam.CenterXOffset += g.xm * g.ballSwayCoupling
+
+	// Les ombres gardent l'échelle de référence de la caméra d'origine : leur W
+	// sert au choix de la teinte et au décalage vertical. Leur taille est
+	// l'échelle de projection multipliée par shadowScale.
+	shadowCam := cam
+	shadowCam.SpriteScale = DefaultCamera().SpriteScale
+
+	count := g.ballCount
+	if g.secondRing {
+		count *= 2
+	}
+	balls := make([]Sprite, count)
+	ballShadows := make([]Sprite, count)
+
+	seg := segmentAt(t, g.animDurations)
+	spin := g.ringSpin(seg, t)
+
+	for i := 0; i < g.ballCount; i++ {
+		// Calculer l'alpha pour le blend entre deux animations
+		alpha := blendAlpha(seg.Elapsed, g.blendDuration, seg.Duration)
+
+		// Obtenir les deux mouvements à mélanger : "b" est exactement le mouvement
+		// du segment suivant, pour que la fin d'un segment raccorde avec le début
+		// du suivant (y compris aux rebouclages 7 -> 0 et 1 -> 2)
+		a := getMovement(seg.Index, t, i)
+		b := getMovement(seg.Next, t, i)
+		anim := blendAnim(a, b, alpha)
+		anim.SpinSpeed = spin
 
-		// IMPORTANT: Accumuler currentRadians AVANT de l'utiliser
-		// Réduire la vitesse de rotation pour plus de fluidité
-		g.currentRadians += (math.Pi * 2 / 360) * anim.SpinSpeed * 0.15 // Changé de 0.2 à 0.15
-		g.currentRadians = math.Mod(g.currentRadians, math.Pi*2)
-		p.RotateY(g.currentRadians)
+		// updateRing a fait avancer l'anneau d'un pas par boule : la boule i
+		// est en retard des pas des boules suivantes
+		behind := float64(g.ballCount-1-i) * spin
+		p := g.clampToGround(ringPosition(anim, i, accumulateRadians(g.currentRadians, -behind), g.ringTilt))
 
 		// Position de l'ombre (au sol)
-		ps := Vec3{X: p.X, Y: 60, Z: p.Z}
+		ps := g.shadowPoint(p)
 
 		// Créer les sprites pour la boule et son ombre
-		balls[i] = NewSprite(p, FOCAL_LENGTH, screenWidth, screenHeight)
-		ballShadows[i] = NewSprite(ps, FOCAL_LENGTH, screenWidth, screenHeight)
+		balls[i] = g.project(cam, p)
+		ballShadows[i] = g.project(shadowCam, ps)
+
+		// Second anneau : même chorégraphie, rotation inverse et rayon décalé
+		if g.secondRing {
+			anim2 := anim
+			anim2.SpinSpeed = -anim.SpinSpeed
+			anim2.RadiusFromCenterOfScreen += g.secondRingRadiusOffset
+
+			p2 := g.clampToGround(ringPosition(anim2, i, accumulateRadians(g.secondRingRadians, behind), g.ringTilt))
+			ps2 := g.shadowPoint(p2)
+
+			balls[g.ballCount+i] = g.project(cam, p2)
+			ballShadows[g.ballCount+i] = g.project(shadowCam, ps2)
+		}
 	}
 
-	// Trier par profondeur Z (plus loin en premier)
-	// Créer des indices pour maintenir la correspondance boule/ombre
-	indices := []int{0, 1, 2, 3}
-	for i := 0; i < 3; i++ {
-		for j := i + 1; j < 4; j++ {
-			if balls[indices[i]].Z < balls[indices[j]].Z {
-				indices[i], indices[j] = indices[j], indices[i]
+	indices := g.drawOrder(balls)
+
+	// Mise en place progressive : les boules pas encore apparues sont ignorées
+	if g.buildUp {
+		kept := indices[:0]
+		for _, idx := range indices {
+			if g.buildUpAlpha(idx%g.ballCount) > 0 {
+				kept = append(kept, idx)
 			}
 		}
+		indices = kept
+	}
+
+	// Dessiner les reflets sur le damier (dans l'ordre de profondeur)
+	if g.floorReflection {
+		sy := g.layoutScaleY()
+		floorTop := int(g.floorY * sy)
+		floorBottom := int((g.floorY + float64(g.chessboard.Bounds().Dy())*g.floorScaleY) * sy)
+		floor := screen.SubImage(image.Rect(0, floorTop, screen.Bounds().Dx(), floorBottom)).(*ebiten.Image)
+
+		for _, idx := range indices {
+			sphere := g.spheres[idx%len(g.spheres)]
+			halfW, halfH := spriteHalfSize(sphere)
+
+			// Symétrie verticale de la boule par rapport à son point de contact au sol
+			op := &ebiten.DrawImageOptions{}
+			op.GeoM.Scale(balls[idx].W, -balls[idx].W)
+			op.GeoM.Translate(
+				balls[idx].U-halfW,
+				2*ballShadows[idx].V-(balls[idx].V-halfH),
+			)
+			op.ColorScale.ScaleAlpha(0.3 * float32(g.buildUpAlpha(idx%g.ballCount)))
+			op.Filter = g.spriteFilter()
+			floor.DrawImage(sphere, op)
+		}
 	}
 
 	// Dessiner les ombres d'abord (dans l'ordre de profondeur)
@@ -691,105 +2495,1288 @@ func (g *Game) drawDoc(screen *ebiten.Image) {
 		shadowColor := int(((ballShadows[idx].W - 0.5) * 10) / 2)
 		shadowColor = 3 - max(0, min(3, shadowColor))
 
-		verticalDisplace := math.Min(1, math.Max(0, 1-ballShadows[idx].W)) * 26
+		verticalDisplace := shadowLiftAt(ballShadows[idx].W, g.shadowLift, g.shadowLiftCurve)
+
+		shadow := g.shadows[shadowColor]
+		halfW, halfH := spriteHalfSize(shadow)
+
+		s := shadowCam.Scale(ballShadows[idx].Z) * g.shadowScale
+
+		// Ombre continue : un seul sprite, atténué avec la distance au lieu de
+		// passer d'un niveau pré-rendu à l'autre
+		shadowAlpha := 1.0
+		if g.continuousShadow {
+			shadow = g.shadows[0]
+			halfW, halfH = spriteHalfSize(shadow)
+			shadowAlpha = continuousShadowAlpha(ballShadows[idx].W)
+		}
 
 		op := &ebiten.DrawImageOptions{}
-		op.GeoM.Scale(ballShadows[idx].W, ballShadows[idx].W)
+		op.GeoM.Scale(s, s)
 		op.GeoM.Translate(
-			ballShadows[idx].U-SHADOW_WIDTH*0.5,
-			ballShadows[idx].V-SHADOW_HEIGHT*0.5-verticalDisplace,
+			ballShadows[idx].U-halfW,
+			ballShadows[idx].V-halfH-verticalDisplace,
 		)
-		screen.DrawImage(g.shadows[shadowColor], op)
+		op.ColorScale.ScaleAlpha(float32(shadowAlpha * g.buildUpAlpha(idx%g.ballCount)))
+		op.Filter = g.spriteFilter()
+		screen.DrawImage(shadow, op)
 	}
 
 	// Dessiner les sphères (dans l'ordre de profondeur)
 	for _, idx := range indices {
+		// La texture dépend de l'index d'origine de la boule, pas de l'ordre de tri
+		sphere := g.spheres[idx%len(g.spheres)]
+		halfW, halfH := spriteHalfSize(sphere)
+
 		op := &ebiten.DrawImageOptions{}
 		op.GeoM.Scale(balls[idx].W, balls[idx].W)
 		op.GeoM.Translate(
-			balls[idx].U-BALL_WIDTH*0.5,
-			balls[idx].V-BALL_HEIGHT*0.5,
+			balls[idx].U-halfW,
+			balls[idx].V-halfH,
 		)
-		screen.DrawImage(g.sphere, op)
+		if g.fogStrength > 0 {
+			f := cam.fogFactor(balls[idx].W, g.fogStrength)
+			op.ColorScale.Scale(f, f, f, 1)
+		}
+		op.ColorScale.ScaleAlpha(float32(g.buildUpAlpha(idx % g.ballCount)))
+		op.Filter = g.spriteFilter()
+		if g.ballAdditive {
+			// Les couleurs s'additionnent : l'ordre de dessin n'a plus d'effet
+			// visible, le tri par profondeur ne sert qu'aux ombres et reflets
+			op.CompositeMode = ebiten.CompositeModeLighter
+		}
+
+		if g.useShaderBalls && g.ballShader != nil {
+			g.drawShadedBall(screen, sphere, op)
+			continue
+		}
+		screen.DrawImage(sphere, op)
+	}
+
+	// Projecteur centré sur la position moyenne des sphères à l'écran
+	if g.spotlight && len(indices) > 0 {
+		var cx, cy float64
+		for _, idx := range indices {
+			cx += balls[idx].U
+			cy += balls[idx].V
+		}
+		cx /= float64(len(indices))
+		cy /= float64(len(indices))
+
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Translate(cx-float64(g.width), cy-float64(g.height))
+		op.ColorScale.ScaleAlpha(0.85)
+		screen.DrawImage(g.spotlightImage, op)
 	}
 }
 
+// drawPaths trace, pour chaque boule, le chemin qu'elle suivra jusqu'à la fin
+// de la forme d'onde courante (sans le blend de transition). La rotation est
+// simulée image par image, comme dans updateRing où currentRadians avance une
+// fois par boule et par image.
+func (g *Game) drawPaths(screen *ebiten.Image) {
+	cam := g.camera
+	cam.CenterXOffset += g.xm * g.ballSwayCoupling
+
+	seg := segmentAt(g.animTime, g.animDurations)
+	dt := 1 / float64(ebiten.TPS())
+	frames := int((seg.Duration - seg.Elapsed) / dt)
+
+	pathColor := color.RGBA{255, 255, 255, 64}
+	prev := make([]Sprite, g.ballCount)
+	radians := g.currentRadians
+
+	for f := 0; f <= frames; f++ {
+		t := g.animTime + float64(f)*dt
+		for i := 0; i < g.ballCount; i++ {
+			anim := getMovement(seg.Index, t, i)
+			radians = accumulateRadians(radians, anim.SpinSpeed*g.spinMultiplier)
+			s := g.project(cam, ringPosition(anim, i, radians, g.ringTilt))
+
+			// Un trait toutes les 4 images suffit pour une courbe lisse
+			if f%4 != 0 {
+				continue
+			}
+			if f > 0 && cam.Visible(prev[i].Z) && cam.Visible(s.Z) {
+				vector.StrokeLine(screen, float32(prev[i].U), float32(prev[i].V), float32(s.U), float32(s.V), 1, pathColor, true)
+			}
+			prev[i] = s
+		}
+	}
+}
+
+// spriteFilter retourne le filtrage des sprites de boules et d'ombres : linéaire
+// pour éviter le scintillement des petites tailles, au plus proche sinon (d'origine)
+func (g *Game) spriteFilter() ebiten.Filter {
+	if g.linearFilter {
+		return ebiten.FilterLinear
+	}
+	return ebiten.FilterNearest
+}
+
+// buildUpAlpha retourne l'opacité de la boule i pendant la mise en place
+// progressive : une boule au départ, puis les suivantes apparaissent en fondu
+// jusqu'à ballCount à la fin de buildUpDuration, comptée depuis le saut.
+func (g *Game) buildUpAlpha(i int) float64 {
+	if !g.buildUp || g.buildUpDuration <= 0 || g.ballCount <= 1 {
+		return 1
+	}
+
+	progress := math.Min(1, (g.animTime-g.jumpTime)/g.buildUpDuration)
+	active := 1 + progress*float64(g.ballCount-1)
+	return math.Max(0, math.Min(1, active-float64(i)))
+}
+
+// clampToGround remonte une boule qui traverserait le sol quand clampToFloor
+// est actif. Le rayon en unités du monde est la demi-hauteur du sprite
+// multipliée par le SpriteScale de la caméra, comme lors de la projection.
+func (g *Game) clampToGround(p Vec3) Vec3 {
+	if !g.clampToFloor || g.sphere == nil {
+		return p
+	}
+
+	radius := float64(g.sphere.Bounds().Dy()) / 2 * g.camera.SpriteScale
+	p.Y = math.Min(p.Y, g.groundY-radius)
+	return p
+}
+
+// shadowPoint retourne la position au sol de l'ombre d'une boule en p
+func (g *Game) shadowPoint(p Vec3) Vec3 {
+	return Vec3{X: p.X, Y: g.groundY, Z: p.Z}
+}
+
+// drawShadedBall dessine une sphère éclairée par le shader, dans le même
+// rectangle écran que le sprite qu'elle remplace
+func (g *Game) drawShadedBall(screen, sphere *ebiten.Image, spriteOp *ebiten.DrawImageOptions) {
+	b := sphere.Bounds()
+	x0, y0 := spriteOp.GeoM.Apply(0, 0)
+	x1, y1 := spriteOp.GeoM.Apply(float64(b.Dx()), float64(b.Dy()))
+	w, h := int(math.Ceil(x1-x0)), int(math.Ceil(y1-y0))
+	if w <= 0 || h <= 0 {
+		return
+	}
+
+	c := g.shaderBallColor
+	op := &ebiten.DrawRectShaderOptions{}
+	op.GeoM.Translate(x0, y0)
+	op.ColorScale = spriteOp.ColorScale
+	op.CompositeMode = spriteOp.CompositeMode
+	op.Uniforms = map[string]any{
+		"Center":    []float32{float32((x0 + x1) / 2), float32((y0 + y1) / 2)},
+		"Radius":    float32(math.Min(x1-x0, y1-y0) / 2),
+		"BaseColor": []float32{float32(c.R) / 255, float32(c.G) / 255, float32(c.B) / 255, float32(c.A) / 255},
+		"LightDir":  []float32{-0.5, -0.6, 0.6},
+	}
+	screen.DrawRectShader(w, h, g.ballShader, op)
+}
+
+// backdropScale retourne l'étirement horizontal pour que le fond couvre l'écran
+func backdropScale(backdrop *ebiten.Image, width int) float64 {
+	return float64(width) / float64(backdrop.Bounds().Dx())
+}
+
+// Run configure la fenêtre et lance la boucle de jeu jusqu'à sa fermeture.
+// Init doit avoir été appelé ; l'erreur de RunGame est retournée à l'appelant.
+func (g *Game) Run() error {
+	ebiten.SetWindowSize(g.WindowWidth, g.WindowHeight)
+	ebiten.SetWindowTitle(g.WindowTitle)
+	ebiten.SetFullscreen(g.fullscreen)
+
+	return ebiten.RunGame(g)
+}
+
 // Update met à jour l'état du jeu
 func (g *Game) Update() error {
-	if !g.jump {
-		// Phase d'intro - détecter le caractère '\'
-		charIndex := int(g.scrollX1 / float64(fontWidth))
-		if charIndex < len(g.text1) && g.text1[charIndex] == '\\' {
-			g.jump = true
+	g.updateMusicFade()
+	g.updateMinimized()
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyF) {
+		g.togglePhotoMode()
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyI) {
+		g.invert = !g.invert
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyL) {
+		g.showPaths = !g.showPaths
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyG) {
+		g.showGrid = !g.showGrid
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyS) {
+		g.scrollerPaused = !g.scrollerPaused
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyBracketLeft) {
+		g.SetSpinMultiplier(g.spinMultiplier - spinMultiplierStep)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyBracketRight) {
+		g.SetSpinMultiplier(g.spinMultiplier + spinMultiplierStep)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyT) {
+		g.cycleTheme()
+	}
+	if g.photoMode {
+		// L'animation est suspendue pendant le mode photo
+		g.updatePhotoMode()
+		return nil
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyP) {
+		g.paused = !g.paused
+		g.pauseCaptured = false
+	}
+
+	// Les calques restent commutables en pause : l'image figée est redessinée
+	mask := g.layerMask
+	g.handleLayerKeys()
+	if g.layerMask != mask {
+		g.pauseCaptured = false
+	}
+
+	if g.paused {
+		// En pause, '.' avance d'une image et ',' revient à la précédente
+		step := inpututil.IsKeyJustPressed(ebiten.KeyPeriod)
+		if inpututil.IsKeyJustPressed(ebiten.KeyComma) {
+			step = g.restorePreviousState()
 		}
-		g.scrollX1 = math.Mod(g.scrollX1+2, float64(len(g.text1))*float64(fontWidth))
-	} else {
-		// Animation principale
-		g.speed = -1 * math.Cos(g.vbl/40)
-		g.vbl += 0.16
-		g.xm = 128 * math.Cos(g.vbl2/40)
-		g.vbl2 += 0.8
+		if !step {
+			return nil
+		}
+		g.pauseCaptured = false
+	}
+
+	g.recordState()
+	g.tick()
+
+	return nil
+}
+
+// tick fait avancer l'horloge et l'animation de la phase courante d'un tick,
+// sans lire le clavier
+func (g *Game) tick() {
+	g.animTime += 1 / float64(ebiten.TPS())
+
+	// Fin de la boucle : passer à l'outro ou repartir du début
+	if g.loopDuration > 0 && g.animTime >= g.loopDuration && g.phase != PhaseOutro {
+		if g.outro {
+			g.setPhase(PhaseOutro)
+		} else {
+			g.restart()
+		}
+	}
+
+	switch g.phase {
+	case PhaseIntro:
+		g.updateIntro()
+	case PhaseMain:
+		g.updateMain()
+	case PhaseOutro:
+		g.updateOutro()
+	}
+}
+
+// updateIntro fait défiler le texte d'intro et passe à la scène principale
+// sur le caractère '\' ou à l'expiration du délai
+func (g *Game) updateIntro() {
+	if !fontReady(g.introFont) {
+		g.introFont = g.font1
+	}
+
+	// Phase d'intro - détecter le caractère '\'
+	charIndex, _ := g.scrollStart(g.introFont, g.text1, g.scrollX1)
+	jump := charIndex < len(g.text1) && g.text1[charIndex] == '\\'
+
+	// Sécurité pour les textes d'intro sans caractère '\'
+	// (l'intro démarre toujours à animTime = 0)
+	if g.introTimeout > 0 && g.animTime >= g.introTimeout {
+		jump = true
+	}
+	if jump {
+		g.setPhase(PhaseMain)
+	}
+	g.scrollX1 = math.Mod(g.scrollX1+g.scrollSpeed(g.introSpeed), g.textWidth(g.introFont, g.text1))
+}
+
+// scrollSpeed retourne la vitesse de défilement effective : nulle quand les
+// scrollers sont figés
+func (g *Game) scrollSpeed(speed float64) float64 {
+	if g.scrollerPaused {
+		return 0
+	}
+	return speed
+}
+
+// updateMain fait avancer le balancement et la vitesse du damier, puis le
+// damier, le scroller et l'anneau de boules. Le dessin ne modifie pas l'état :
+// une image sautée (fenêtre réduite) ou redessinée (pause) ne décale rien.
+func (g *Game) updateMain() {
+	// Animation principale
+	g.speed = -1 * math.Cos(g.vbl/40) * g.speedRamp()
+	g.vbl += 0.16
+	g.xm = g.swayAmplitude * math.Cos(g.vbl2*g.swayFrequency) * g.motionScale()
+	g.vbl2 += 0.8
+
+	g.updateChessboard()
+	g.updateScroller()
+	g.updateRing()
+}
+
+// updateChessboard fait défiler le damier et les montagnes selon le balancement
+func (g *Game) updateChessboard() {
+	g.xMove += g.xm * g.speed * 0.005
+	if g.xMove > 32 {
+		g.xMove -= 32
+	}
+	if g.xMove < 0 {
+		g.xMove += 32
+	}
+
+	// Parallaxe des montagnes, proportionnelle au déplacement du damier
+	if g.parallaxFactor != 0 && g.mountains != nil {
+		w := float64(g.mountains.Bounds().Dx())
+		g.mountainsX = math.Mod(g.mountainsX+g.xm*g.speed*0.005*g.parallaxFactor, w)
+		if g.mountainsX < 0 {
+			g.mountainsX += w
+		}
+	}
+
+	g.yMove += g.ym * g.speed * 0.016 * g.motionScale()
+	if g.yMove > 64 {
+		g.yMove -= 64
+	}
+	if g.yMove < 0 {
+		g.yMove += 64
+	}
+}
+
+// updateScroller fait avancer le texte, la vague et le rebond du scroller
+// principal. Masqué ou figé, il reste en place.
+func (g *Game) updateScroller() {
+	if !g.showScroller || g.scrollerPaused {
+		return
+	}
+	if !fontReady(g.mainFont) {
+		g.mainFont = g.fontOut
+	}
+
+	speed := g.mainSpeed
+	if g.scrollReverse {
+		speed = -speed
+	}
+	if fontReady(g.mainFont) {
+		g.scrollX2 = advanceScroll(g.scrollX2, speed, g.textWidth(g.mainFont, g.text2))
+		g.advanceScrollQueue()
+	}
+
+	g.vbl4 += 1.2
+	g.vbl3++
+}
+
+// ringSpin retourne la vitesse de rotation de l'anneau à l'instant t (lissée
+// si spinSmoothing est actif, multipliée par spinMultiplier). Elle ne dépend
+// pas de la boule : chaque boule fait avancer l'anneau du même pas.
+func (g *Game) ringSpin(seg animSegment, t float64) float64 {
+	spin := g.smoothedSpin
+	if g.spinSmoothing <= 0 {
+		alpha := blendAlpha(seg.Elapsed, g.blendDuration, seg.Duration)
+		spin = blendAnim(getMovement(seg.Index, t, 0), getMovement(seg.Next, t, 0), alpha).SpinSpeed
+	}
+	return spin * g.spinMultiplier
+}
+
+// updateRing fait tourner l'anneau d'un pas par boule, comme l'animation
+// d'origine, et le second anneau en sens inverse
+func (g *Game) updateRing() {
+	t := g.animTime
+	seg := segmentAt(t, g.animDurations)
+
+	// Lissage optionnel de la vitesse de rotation, commune à toutes les boules
+	if g.spinSmoothing > 0 {
+		alpha := blendAlpha(seg.Elapsed, g.blendDuration, seg.Duration)
+		target := blendAnim(getMovement(seg.Index, t, 0), getMovement(seg.Next, t, 0), alpha).SpinSpeed
+		g.smoothedSpin = smoothSpin(g.smoothedSpin, target, g.spinSmoothing)
+	}
+
+	spin := g.ringSpin(seg, t)
+	for i := 0; i < g.ballCount; i++ {
+		g.currentRadians = accumulateRadians(g.currentRadians, spin)
+		if g.secondRing {
+			g.secondRingRadians = accumulateRadians(g.secondRingRadians, -spin)
+		}
+	}
+}
+
+// updateOutro fait défiler le message final
+func (g *Game) updateOutro() {
+	if g.outroText != "" && fontReady(g.font1) {
+		g.scrollX3 = advanceScroll(g.scrollX3, 2, g.textWidth(g.font1, g.outroText))
+	}
+}
+
+// setPhase change de phase en mémorisant l'instant de la transition
+func (g *Game) setPhase(p Phase) {
+	g.phase = p
+	switch p {
+	case PhaseMain:
+		g.jumpTime = g.animTime
+	case PhaseOutro:
+		g.outroTime = g.animTime
+	}
+}
+
+// updateMusicFade ajuste le volume pendant le fondu d'entrée et autour du
+// point de boucle. L'horloge du fondu d'entrée est indépendante de animTime :
+// la musique continue en pause.
+func (g *Game) updateMusicFade() {
+	if g.audioPlayer == nil {
+		return
+	}
+
+	volume := 1.0
+	fading := false
+	if g.musicFadeIn > 0 && g.musicTime < g.musicFadeIn {
+		g.musicTime += 1 / float64(ebiten.TPS())
+		volume = math.Min(1, g.musicTime/g.musicFadeIn)
+		fading = true
+	}
+	if g.loopDuration > 0 && g.musicLoopFade > 0 {
+		volume *= g.musicLoopVolume()
+		fading = true
+	}
+
+	if fading {
+		g.audioPlayer.SetVolume(volume)
+	}
+}
+
+// updateMinimized détecte la réduction de la fenêtre. Toute l'animation avance
+// dans Update : sauter Draw ne la décale pas et la reprise est transparente.
+// La musique n'est suspendue que si pauseAudioMinimized est actif.
+func (g *Game) updateMinimized() {
+	minimized := ebiten.IsWindowMinimized() || g.layoutEmpty
+	if minimized == g.minimized {
+		return
+	}
+	g.minimized = minimized
+
+	if g.audioPlayer != nil && g.pauseAudioMinimized {
+		if minimized {
+			g.audioPlayer.Pause()
+		} else {
+			g.audioPlayer.Play()
+		}
+	}
+}
+
+// SetPauseAudioWhenMinimized suspend la musique pendant que la fenêtre est
+// réduite (par défaut elle continue de jouer)
+func (g *Game) SetPauseAudioWhenMinimized(on bool) {
+	g.pauseAudioMinimized = on
+}
+
+// musicLoopVolume retourne le volume (0 à 1) de la musique autour du point de
+// boucle : descente avant la fin, remontée après le redémarrage. Avec l'outro,
+// la démo ne reboucle pas et la musique garde son volume.
+func (g *Game) musicLoopVolume() float64 {
+	if g.outro || g.phase == PhaseOutro {
+		return 1
+	}
+	if remaining := g.loopDuration - g.animTime; remaining < g.musicLoopFade {
+		return math.Max(0, remaining/g.musicLoopFade)
+	}
+	if g.looped && g.animTime < g.musicLoopFade {
+		return g.animTime / g.musicLoopFade
+	}
+	return 1
+}
+
+// speedRamp retourne le facteur (0 à 1) appliqué à la vitesse du damier
+// juste après le saut, pour éviter l'à-coup du départ à pleine vitesse
+func (g *Game) speedRamp() float64 {
+	if g.speedEaseIn <= 0 {
+		return 1
+	}
+	r := math.Max(0, math.Min(1, (g.animTime-g.jumpTime)/g.speedEaseIn))
+	return r * r * (3 - 2*r)
+}
+
+// layerVisible indique si un calque doit être dessiné
+func (g *Game) layerVisible(layer uint8) bool {
+	return g.layerMask&layer != 0
+}
+
+// handleLayerKeys bascule la visibilité des calques avec les touches 1 à 5
+func (g *Game) handleLayerKeys() {
+	keys := []ebiten.Key{ebiten.KeyDigit1, ebiten.KeyDigit2, ebiten.KeyDigit3, ebiten.KeyDigit4, ebiten.KeyDigit5}
+	for i, key := range keys {
+		if inpututil.IsKeyJustPressed(key) {
+			g.layerMask ^= layerNames[i].layer
+		}
+	}
+}
+
+// drawLayerOverlay liste les calques actifs quand certains sont masqués
+func (g *Game) drawLayerOverlay(screen *ebiten.Image) {
+	if g.layerMask == layerAll {
+		return
+	}
+
+	text := "layers:"
+	for i, l := range layerNames {
+		state := "off"
+		if g.layerVisible(l.layer) {
+			state = "on"
+		}
+		text += fmt.Sprintf("\n%d %s: %s", i+1, l.name, state)
+	}
+	ebitenutil.DebugPrint(screen, text)
+}
+
+// drawGrid superpose une grille tous les gridStep pixels, avec les
+// coordonnées écrites en petit le long des axes une ligne sur deux
+func (g *Game) drawGrid(screen *ebiten.Image) {
+	const (
+		gridStep   = 32
+		labelScale = 0.2
+	)
+	lineColor := color.RGBA{0x40, 0x40, 0x40, 0x40}
+
+	for x := 0; x < g.width; x += gridStep {
+		vector.StrokeLine(screen, float32(x)+0.5, 0, float32(x)+0.5, float32(g.height), 1, lineColor, false)
+		if x > 0 && x%(2*gridStep) == 0 {
+			g.drawText(screen, g.font1, fmt.Sprint(x), float64(x)+2, 2, labelScale)
+		}
+	}
+	for y := 0; y < g.height; y += gridStep {
+		vector.StrokeLine(screen, 0, float32(y)+0.5, float32(g.width), float32(y)+0.5, 1, lineColor, false)
+		if y > 0 && y%(2*gridStep) == 0 {
+			g.drawText(screen, g.font1, fmt.Sprint(y), 2, float64(y)+2, labelScale)
+		}
+	}
+}
+
+// togglePhotoMode entre ou sort du mode photo, en réinitialisant le zoom
+func (g *Game) togglePhotoMode() {
+	g.photoMode = !g.photoMode
+	g.photoCaptured = false
+	g.photoZoom = 1
+	g.photoPanX, g.photoPanY = 0, 0
+}
+
+// updatePhotoMode gère le zoom à la molette et le déplacement à la souris
+func (g *Game) updatePhotoMode() {
+	cx, cy := ebiten.CursorPosition()
+
+	if _, dy := ebiten.Wheel(); dy != 0 {
+		zoom := math.Max(1, math.Min(16, g.photoZoom*math.Pow(1.1, dy)))
+
+		// Garder le point sous le curseur immobile pendant le zoom
+		fx := (float64(cx) - g.photoPanX) / g.photoZoom
+		fy := (float64(cy) - g.photoPanY) / g.photoZoom
+		g.photoPanX = float64(cx) - fx*zoom
+		g.photoPanY = float64(cy) - fy*zoom
+		g.photoZoom = zoom
+	}
+
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		g.photoDragX, g.photoDragY = cx, cy
+	} else if ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
+		g.photoPanX += float64(cx - g.photoDragX)
+		g.photoPanY += float64(cy - g.photoDragY)
+		g.photoDragX, g.photoDragY = cx, cy
 	}
+}
+
+// drawPhotoMode fige l'image courante à l'entrée du mode puis l'affiche zoomée
+func (g *Game) drawPhotoMode(dst *ebiten.Image) {
+	if g.photoFrame == nil || g.photoFrame.Bounds().Dx() != g.width || g.photoFrame.Bounds().Dy() != g.height {
+		if g.photoFrame != nil {
+			g.photoFrame.Dispose()
+		}
+		g.photoFrame = ebiten.NewImage(g.width, g.height)
+		g.photoCaptured = false
+	}
+	if !g.photoCaptured {
+		g.drawFrame(g.photoFrame)
+		g.photoCaptured = true
+	}
+
+	dst.Fill(color.Black)
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(g.photoZoom, g.photoZoom)
+	op.GeoM.Translate(g.photoPanX, g.photoPanY)
+	op.GeoM.Translate(float64(dst.Bounds().Min.X), float64(dst.Bounds().Min.Y))
+	dst.DrawImage(g.photoFrame, op)
+}
+
+// drawPaused affiche l'image figée, recalculée seulement après un pas ou un
+// changement de calques
+func (g *Game) drawPaused(dst *ebiten.Image) {
+	if g.pauseFrame == nil || g.pauseFrame.Bounds().Dx() != g.width || g.pauseFrame.Bounds().Dy() != g.height {
+		if g.pauseFrame != nil {
+			g.pauseFrame.Dispose()
+		}
+		g.pauseFrame = ebiten.NewImage(g.width, g.height)
+		g.pauseCaptured = false
+	}
+	if !g.pauseCaptured {
+		g.drawFrame(g.pauseFrame)
+		g.pauseCaptured = true
+	}
+
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(float64(dst.Bounds().Min.X), float64(dst.Bounds().Min.Y))
+	dst.DrawImage(g.pauseFrame, op)
+}
+
+// recordState mémorise l'état avant chaque tick, en oubliant les plus anciens
+func (g *Game) recordState() {
+	if g.stateHistory == nil {
+		g.stateHistory = make([]gameState, stateHistorySize)
+	}
+
+	g.stateHistory[g.stateNext] = g.captureState()
+	g.stateNext = (g.stateNext + 1) % stateHistorySize
+	g.stateCount = min(g.stateCount+1, stateHistorySize)
+}
+
+// restorePreviousState revient à l'état précédant l'image affichée ; le tick
+// qui suit la rejoue. Retourne false si l'historique est épuisé.
+func (g *Game) restorePreviousState() bool {
+	if g.stateCount < 2 {
+		return false
+	}
+
+	// L'état le plus récent est celui de l'image affichée : reprendre l'avant-dernier
+	prev := (g.stateNext - 2 + stateHistorySize) % stateHistorySize
+	g.applyState(g.stateHistory[prev])
+	g.stateNext = prev
+	g.stateCount -= 2
+	return true
+}
+
+// gameState regroupe les champs d'animation modifiables, sans les ressources
+type gameState struct {
+	Vbl               float64 `json:"vbl"`
+	Vbl2              float64 `json:"vbl2"`
+	Vbl3              int     `json:"vbl3"`
+	Vbl4              float64 `json:"vbl4"`
+	XMove             float64 `json:"xMove"`
+	YMove             float64 `json:"yMove"`
+	Xm                float64 `json:"xm"`
+	Speed             float64 `json:"speed"`
+	MountainsX        float64 `json:"mountainsX"`
+	ScrollX1          float64 `json:"scrollX1"`
+	ScrollX2          float64 `json:"scrollX2"`
+	ScrollX3          float64 `json:"scrollX3"`
+	CurrentRadians    float64 `json:"currentRadians"`
+	SmoothedSpin      float64 `json:"smoothedSpin"`
+	SecondRingRadians float64 `json:"secondRingRadians"`
+	AnimTime          float64 `json:"animTime"`
+	Phase             Phase   `json:"phase"`
+	JumpTime          float64 `json:"jumpTime"`
+	Looped            bool    `json:"looped"`
+	OutroTime         float64 `json:"outroTime"`
+	ScrollQueueIndex  int     `json:"scrollQueueIndex"`
+	ScrollerPaused    bool    `json:"scrollerPaused"`
+}
+
+// MarshalState sérialise en JSON l'état courant de l'animation
+func (g *Game) MarshalState() ([]byte, error) {
+	return json.Marshal(g.captureState())
+}
 
+// LoadState restaure un état produit par MarshalState
+func (g *Game) LoadState(data []byte) error {
+	var st gameState
+	if err := json.Unmarshal(data, &st); err != nil {
+		return fmt.Errorf("failed to load state: %v", err)
+	}
+	g.applyState(st)
 	return nil
 }
 
+// captureState copie l'état courant de l'animation
+func (g *Game) captureState() gameState {
+	return gameState{
+		Vbl:               g.vbl,
+		Vbl2:              g.vbl2,
+		Vbl3:              g.vbl3,
+		Vbl4:              g.vbl4,
+		XMove:             g.xMove,
+		YMove:             g.yMove,
+		Xm:                g.xm,
+		Speed:             g.speed,
+		MountainsX:        g.mountainsX,
+		ScrollX1:          g.scrollX1,
+		ScrollX2:          g.scrollX2,
+		ScrollX3:          g.scrollX3,
+		CurrentRadians:    g.currentRadians,
+		SmoothedSpin:      g.smoothedSpin,
+		SecondRingRadians: g.secondRingRadians,
+		AnimTime:          g.animTime,
+		Phase:             g.phase,
+		JumpTime:          g.jumpTime,
+		Looped:            g.looped,
+		OutroTime:         g.outroTime,
+		ScrollQueueIndex:  g.scrollQueueIndex,
+		ScrollerPaused:    g.scrollerPaused,
+	}
+}
+
+// applyState restaure un état copié par captureState. Le message de la file
+// est remis en place sans changer la position du scroller.
+func (g *Game) applyState(st gameState) {
+	g.vbl, g.vbl2, g.vbl3, g.vbl4 = st.Vbl, st.Vbl2, st.Vbl3, st.Vbl4
+	g.xMove, g.yMove = st.XMove, st.YMove
+	g.xm, g.speed = st.Xm, st.Speed
+	g.mountainsX = st.MountainsX
+	g.scrollX1, g.scrollX2, g.scrollX3 = st.ScrollX1, st.ScrollX2, st.ScrollX3
+	g.currentRadians, g.secondRingRadians = st.CurrentRadians, st.SecondRingRadians
+	g.smoothedSpin = st.SmoothedSpin
+	g.animTime = st.AnimTime
+	g.phase = st.Phase
+	g.jumpTime = st.JumpTime
+	g.looped = st.Looped
+	g.outroTime = st.OutroTime
+	g.scrollerPaused = st.ScrollerPaused
+
+	g.scrollQueueIndex = -1
+	if st.ScrollQueueIndex >= 0 && st.ScrollQueueIndex < len(g.scrollQueue) {
+		g.layoutQueuedMessage(st.ScrollQueueIndex)
+	}
+}
+
+// restart remet l'animation à son état initial pour rejouer la démo
+func (g *Game) restart() {
+	g.animTime = 0
+	g.looped = true
+	if g.loopReplayIntro {
+		g.setPhase(PhaseIntro)
+	} else {
+		g.setPhase(PhaseMain)
+	}
+
+	g.vbl, g.vbl2, g.vbl3, g.vbl4 = 0, 0, 0, 0
+	g.xMove, g.yMove = 0, 0
+	g.xm, g.speed = 0, 1
+	g.mountainsX = 0
+	g.scrollX1, g.scrollX2, g.scrollX3 = 0, 0, 0
+	g.currentRadians, g.secondRingRadians = 0, 0
+	g.smoothedSpin = 0
+	g.jumpTime, g.outroTime = 0, 0
+
+	// La file de messages repart de son premier message
+	if len(g.scrollQueue) > 0 {
+		g.startQueuedMessage(0)
+	}
+}
+
+// loopFadeAlpha retourne l'opacité du fondu au noir autour du point de boucle
+func (g *Game) loopFadeAlpha() float64 {
+	// L'outro gère son propre fondu entrant
+	if g.loopDuration <= 0 || g.loopFade <= 0 || g.phase == PhaseOutro {
+		return 0
+	}
+
+	// Fondu sortant avant la fin de la boucle
+	if remaining := g.loopDuration - g.animTime; remaining < g.loopFade {
+		return math.Min(1, 1-remaining/g.loopFade)
+	}
+
+	// Fondu entrant après un redémarrage
+	if g.looped && g.animTime < g.loopFade {
+		return 1 - g.animTime/g.loopFade
+	}
+
+	return 0
+}
+
 // Draw dessine le jeu
 func (g *Game) Draw(screen *ebiten.Image) {
+	// Rien n'est visible : inutile de composer la scène
+	if g.minimized {
+		return
+	}
+	g.DrawTo(screen)
+}
+
+// DrawTo dessine une image complète de la démo dans dst, qui doit avoir la
+// taille logique du jeu. Une sous-image dont l'origine n'est pas (0, 0) est
+// gérée via un tampon intermédiaire.
+func (g *Game) DrawTo(dst *ebiten.Image) {
+	if g.photoMode {
+		g.drawPhotoMode(dst)
+		return
+	}
+	if g.paused {
+		g.drawPaused(dst)
+		return
+	}
+
+	if dst.Bounds().Min == (image.Point{}) && !g.invert && g.brightness == 1 {
+		g.drawFrame(dst)
+		return
+	}
+
+	// Rendu hors écran puis recopie, avec les effets sur l'image entière
+	buf := g.offscreenBuffer()
+	g.drawFrame(buf)
+
+	if g.invert && g.invertShader != nil {
+		op := &ebiten.DrawRectShaderOptions{}
+		op.GeoM.Translate(float64(dst.Bounds().Min.X), float64(dst.Bounds().Min.Y))
+		op.Images[0] = buf
+		op.ColorScale = g.brightnessScale()
+		dst.DrawRectShader(g.width, g.height, g.invertShader, op)
+		return
+	}
+
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(float64(dst.Bounds().Min.X), float64(dst.Bounds().Min.Y))
+	op.ColorScale = g.brightnessScale()
+	dst.DrawImage(buf, op)
+}
+
+// DrawFrame amène la démo à atSeconds secondes depuis son début et retourne
+// l'image correspondante, que l'appelant doit libérer. Init doit avoir été appelé.
+//
+// Les ticks sont simulés sans être dessinés : le dessin ne modifie pas l'état,
+// et le résultat est identique à une exécution normale de même durée (boucle
+// et outro compris, file de messages repartant de son premier message). Ne
+// sont pas reproduits : les actions au clavier (un scroller figé par S est relancé au
+// redémarrage), le mode photo et la pause. La simulation repart du début, sauf
+// si atSeconds suit l'état courant sans rebouclage, auquel cas elle continue :
+// des appels à temps croissants restent peu coûteux. L'état du jeu est celui
+// de atSeconds au retour.
+func (g *Game) DrawFrame(atSeconds float64) *ebiten.Image {
+	if atSeconds < g.animTime || g.looped {
+		g.restart()
+		g.looped = false
+		g.setPhase(PhaseIntro)
+		g.scrollerPaused = false
+	}
+
+	ticks := int(math.Round((atSeconds - g.animTime) * float64(ebiten.TPS())))
+	for i := 0; i < ticks; i++ {
+		g.tick()
+	}
+
+	frame := ebiten.NewImage(g.width, g.height)
+	g.drawFrame(frame)
+	return frame
+}
+
+// contactSheetScale est la réduction des vignettes d'une planche contact
+const contactSheetScale = 0.25
+
+// GenerateContactSheet rend la démo aux instants times (en secondes) avec
+// DrawFrame et assemble les vignettes réduites en une grille de cols colonnes,
+// dans l'ordre de times. Sans instant, l'image retournée est vide ; cols < 1
+// place toutes les vignettes sur une ligne. Comme FrameHash, elle lit les
+// pixels et doit être appelée pendant que la boucle de jeu tourne.
+func GenerateContactSheet(g *Game, times []float64, cols int) *image.RGBA {
+	if len(times) == 0 {
+		return image.NewRGBA(image.Rect(0, 0, 0, 0))
+	}
+	if cols < 1 || cols > len(times) {
+		cols = len(times)
+	}
+	rows := (len(times) + cols - 1) / cols
+
+	thumbW := int(float64(g.width) * contactSheetScale)
+	thumbH := int(float64(g.height) * contactSheetScale)
+	sheet := ebiten.NewImage(cols*thumbW, rows*thumbH)
+	defer sheet.Dispose()
+
+	for i, t := range times {
+		frame := g.DrawFrame(t)
+
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Scale(contactSheetScale, contactSheetScale)
+		op.GeoM.Translate(float64((i%cols)*thumbW), float64((i/cols)*thumbH))
+		op.Filter = ebiten.FilterLinear
+		sheet.DrawImage(frame, op)
+		frame.Dispose()
+	}
+
+	out := image.NewRGBA(image.Rect(0, 0, cols*thumbW, rows*thumbH))
+	sheet.ReadPixels(out.Pix)
+	return out
+}
+
+// FrameHash fait avancer g de frame+1 ticks (Update puis DrawTo) et retourne
+// un hash FNV-1a des pixels de la dernière image. Partant d'un jeu fraîchement
+// initialisé, le résultat est reproductible car l'animation suit l'horloge
+// des ticks. Comme ReadPixels, elle doit être appelée pendant que la boucle
+// de jeu tourne (par exemple depuis un harnais de test lançant RunGame).
+func FrameHash(g *Game, frame int) (uint64, error) {
+	buf := ebiten.NewImage(g.width, g.height)
+	defer buf.Dispose()
+
+	for i := 0; i <= frame; i++ {
+		if err := g.Update(); err != nil {
+			return 0, err
+		}
+		g.DrawTo(buf)
+	}
+
+	pix := make([]byte, 4*g.width*g.height)
+	buf.ReadPixels(pix)
+
+	h := fnv.New64a()
+	h.Write(pix)
+	return h.Sum64(), nil
+}
+
+// offscreenBuffer retourne un tampon à la taille logique, recréé si elle change
+func (g *Game) offscreenBuffer() *ebiten.Image {
+	if g.offscreen != nil {
+		if g.offscreen.Bounds().Dx() == g.width && g.offscreen.Bounds().Dy() == g.height {
+			return g.offscreen
+		}
+		g.offscreen.Dispose()
+	}
+	g.offscreen = ebiten.NewImage(g.width, g.height)
+	return g.offscreen
+}
+
+// SetLayerEffect associe un effet à un calque (LayerEffect{} pour le retirer)
+func (g *Game) SetLayerEffect(layer uint8, e LayerEffect) {
+	if e == (LayerEffect{}) {
+		delete(g.layerEffects, layer)
+		return
+	}
+	if g.layerEffects == nil {
+		g.layerEffects = make(map[uint8]LayerEffect)
+	}
+	g.layerEffects[layer] = e
+}
+
+// drawLayer dessine un calque visible dans screen. Sans effet, il est dessiné
+// directement ; sinon il passe par un tampon transparent composé avec l'effet.
+func (g *Game) drawLayer(screen *ebiten.Image, layer uint8, draw func(dst *ebiten.Image)) {
+	if !g.layerVisible(layer) {
+		return
+	}
+
+	e, ok := g.layerEffects[layer]
+	if !ok {
+		draw(screen)
+		return
+	}
+
+	if g.layerImage == nil || g.layerImage.Bounds().Dx() != g.width || g.layerImage.Bounds().Dy() != g.height {
+		if g.layerImage != nil {
+			g.layerImage.Dispose()
+		}
+		g.layerImage = ebiten.NewImage(g.width, g.height)
+	}
+	g.layerImage.Clear()
+	draw(g.layerImage)
+
+	img := g.layerImage
+	if e.Blur > 0 {
+		img = g.blur(img, e.Blur)
+	}
+
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(e.OffsetX, e.OffsetY)
+	op.ColorScale = e.Tint
+	screen.DrawImage(img, op)
+}
+
+// blur retourne une copie floutée de src (flou boîte de rayon radius, limité
+// à maxBlurRadius). L'image retournée est réutilisée par l'appel suivant.
+// Sans shader (Init non appelé), src est retournée telle quelle.
+func (g *Game) blur(src *ebiten.Image, radius int) *ebiten.Image {
+	if g.blurShader == nil || radius <= 0 {
+		return src
+	}
+	radius = min(radius, maxBlurRadius)
+
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	for _, img := range []**ebiten.Image{&g.blurTemp, &g.blurImage} {
+		if *img != nil && ((*img).Bounds().Dx() != w || (*img).Bounds().Dy() != h) {
+			(*img).Dispose()
+			*img = nil
+		}
+		if *img == nil {
+			*img = ebiten.NewImage(w, h)
+		}
+	}
+
+	pass := func(dst, src *ebiten.Image, dx, dy float32) {
+		dst.Clear()
+		op := &ebiten.DrawRectShaderOptions{}
+		op.Images[0] = src
+		op.Uniforms = map[string]any{
+			"Radius": float32(radius),
+			"Dir":    []float32{dx, dy},
+		}
+		dst.DrawRectShader(w, h, g.blurShader, op)
+	}
+	pass(g.blurTemp, src, 1, 0)
+	pass(g.blurImage, g.blurTemp, 0, 1)
+	return g.blurImage
+}
+
+// drawBackdrop dessine le fond avec le scale original
+func (g *Game) drawBackdrop(dst *ebiten.Image) {
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(backdropScale(g.backdrop, g.width), 1)
+	op.ColorScale.ScaleWithColor(g.backdropTint)
+	dst.DrawImage(g.backdrop, op)
+}
+
+// drawMountains dessine les montagnes, répétées si la parallaxe est active
+func (g *Game) drawMountains(dst *ebiten.Image) {
+	if g.parallaxFactor == 0 {
+		dst.DrawImage(g.mountains, nil)
+		return
+	}
+
+	// Image répétée pour couvrir le décalage horizontal
+	w := float64(g.mountains.Bounds().Dx())
+	for _, x := range []float64{g.mountainsX - w, g.mountainsX} {
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Translate(x, 0)
+		dst.DrawImage(g.mountains, op)
+	}
+}
+
+// drawFloor dessine le damier préparé par drawChessboard
+func (g *Game) drawFloor(dst *ebiten.Image) {
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM = g.floorGeoM()
+	dst.DrawImage(g.chessboard, op)
+}
+
+// floorGeoM place le damier à l'écran selon floorScaleX, floorScaleY et
+// floorY, l'échelle horizontale suivant la largeur de rendu et la position et
+// l'échelle verticales suivant sa hauteur
+func (g *Game) floorGeoM() ebiten.GeoM {
+	sy := g.layoutScaleY()
+	var m ebiten.GeoM
+	m.Scale(g.floorScaleX*float64(g.width)/screenWidth, g.floorScaleY*sy)
+	m.Translate(0, g.floorY*sy)
+	return m
+}
+
+// drawFrame compose tous les calques de la scène dans screen
+func (g *Game) drawFrame(screen *ebiten.Image) {
 	screen.Fill(color.Black)
 
-	if !g.jump {
-		// Phase d'intro
+	switch g.phase {
+	case PhaseIntro:
+		g.drawIntro(screen)
+	case PhaseMain:
+		g.drawMain(screen)
+	case PhaseOutro:
+		g.drawOutro(screen)
+	}
+
+	// Fondu au noir autour du point de boucle
+	if a := g.loopFadeAlpha(); a > 0 {
+		vector.DrawFilledRect(screen, 0, 0, float32(g.width), float32(g.height), color.RGBA{0, 0, 0, uint8(a * 255)}, false)
+	}
+
+	// Post-effets par-dessus l'image finale
+	if g.vignette {
+		op := &ebiten.DrawImageOptions{}
+		op.ColorScale.ScaleAlpha(float32(g.vignetteStrength))
+		screen.DrawImage(g.vignetteImage, op)
+	}
+	if g.scanlines {
+		op := &ebiten.DrawImageOptions{}
+		op.ColorScale.ScaleAlpha(float32(g.scanlineDarkness))
+		screen.DrawImage(g.scanlineImage, op)
+	}
+
+	// Informations de debug par-dessus tout
+	g.drawLayerOverlay(screen)
+	if g.showGrid {
+		g.drawGrid(screen)
+	}
+}
+
+// drawIntro dessine le scroller d'intro et le titre optionnel
+func (g *Game) drawIntro(screen *ebiten.Image) {
+	if !fontReady(g.introFont) {
+		g.introFont = g.font1
+	}
+
+	// Le texte d'intro n'avance que dans updateIntro, à introSpeed
+	if g.showIntroScroller {
 		g.scrollCanvas1.Clear()
-		g.scrollX1 = g.drawScrollText(g.scrollCanvas1, g.font1, g.text1, g.scrollX1)
+		if g.introReveal && fontReady(g.fontIn) && fontReady(g.fontOut) {
+			g.drawRevealScrollText(g.scrollCanvas1, g.text1, g.scrollX1)
+		} else if g.cacheIntroScroll {
+			g.drawCachedScrollText(g.scrollCanvas1, g.introFont, g.text1, g.scrollX1)
+		} else {
+			g.drawScrollText(g.scrollCanvas1, g.introFont, g.text1, g.scrollX1)
+		}
 
 		op := &ebiten.DrawImageOptions{}
-		op.GeoM.Translate(0, 62)
+		op.GeoM.Translate(0, g.introScrollY*g.layoutScaleY())
 		screen.DrawImage(g.scrollCanvas1, op)
-	} else {
-		// Scène principale
+	}
 
-		// 1. Dessiner le fond avec le scale original
-		op := &ebiten.DrawImageOptions{}
-		op.GeoM.Scale(77, 1)
-		screen.DrawImage(g.backdrop, op)
+	// Titre fixe optionnel, centré sous le scroller
+	if g.introTitle != "" {
+		x := (float64(g.width) - g.textWidth(g.introFont, g.introTitle)) / 2
+		g.drawText(screen, g.introFont, g.introTitle, x, 200, 1)
+	}
+}
 
-		// 2. Dessiner les montagnes
-		screen.DrawImage(g.mountains, nil)
+// drawMain compose les calques de la scène principale
+func (g *Game) drawMain(screen *ebiten.Image) {
+	// 1. Dessiner le fond
+	g.drawLayer(screen, layerBackdrop, g.drawBackdrop)
 
-		// 3. Préparer le damier
-		g.drawChessboard()
+	// 2. Dessiner les montagnes
+	g.drawLayer(screen, layerMountains, g.drawMountains)
 
-		// 4. Dessiner le damier
-		op = &ebiten.DrawImageOptions{}
-		op.GeoM.Scale(0.6, 2.6)
-		op.GeoM.Translate(0, 260)
-		screen.DrawImage(g.chessboard, op)
+	// Mesures de temps uniquement si un callback est enregistré
+	var metrics FrameMetrics
+	measure := g.metricsCallback != nil
+	var start time.Time
 
-		// 5. Dessiner le scroller avec effets
-		g.drawScroller(screen)
+	// 3. Préparer le damier
+	if measure {
+		start = time.Now()
+	}
+	g.drawChessboard()
+	if measure {
+		metrics.Chessboard = time.Since(start)
+	}
 
-		// 6. Dessiner les sphères 3D en tout dernier
-		g.drawDoc(screen)
+	// 4. Dessiner le damier
+	g.drawLayer(screen, layerChessboard, g.drawFloor)
+
+	// 5. Dessiner le scroller avec effets
+	if g.showScroller {
+		g.drawLayer(screen, layerScroller, func(dst *ebiten.Image) {
+			if measure {
+				start = time.Now()
+			}
+			g.drawScroller(dst)
+			if measure {
+				metrics.Scroller = time.Since(start)
+			}
+		})
+	}
+
+	// 6. Dessiner les sphères 3D en tout dernier
+	g.drawLayer(screen, layerBalls, func(dst *ebiten.Image) {
+		if measure {
+			start = time.Now()
+		}
+		g.drawDoc(dst)
+		if measure {
+			metrics.Doc = time.Since(start)
+		}
+	})
+
+	if measure {
+		g.metricsCallback(metrics)
+	}
+
+	if g.showPaths {
+		g.drawPaths(screen)
+	}
+}
+
+// drawOutro affiche le logo en fondu entrant, puis le maintient au-dessus du
+// message final qui défile en bas de l'écran
+func (g *Game) drawOutro(screen *ebiten.Image) {
+	alpha := 1.0
+	if g.loopFade > 0 {
+		alpha = math.Min(1, (g.animTime-g.outroTime)/g.loopFade)
+	}
+
+	if g.logo != nil {
+		b := g.logo.Bounds()
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Translate(float64(g.width-b.Dx())/2, float64(g.height-b.Dy())/2)
+		op.ColorScale.ScaleAlpha(float32(alpha))
+		screen.DrawImage(g.logo, op)
+	}
+
+	if g.outroText != "" {
+		g.scrollCanvas1.Clear()
+		g.drawScrollText(g.scrollCanvas1, g.font1, g.outroText, g.scrollX3)
+
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Translate(0, float64(g.height-g.font1.CellHeight-40))
+		op.ColorScale.ScaleAlpha(float32(alpha))
+		screen.DrawImage(g.scrollCanvas1, op)
 	}
 }
 
 // Layout définit la taille de l'écran
 func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
-	return screenWidth, screenHeight
+	g.layoutEmpty = outsideWidth <= 0 || outsideHeight <= 0
+	return g.width, g.height
+}
+
+// parseOptions lit les options depuis la ligne de commande
+func parseOptions(args []string) (Options, error) {
+	opts := DefaultOptions()
+
+	flags := flag.NewFlagSet("3d_doc", flag.ContinueOnError)
+	flags.IntVar(&opts.WindowWidth, "width", opts.WindowWidth, "window width in pixels")
+	flags.IntVar(&opts.WindowHeight, "height", opts.WindowHeight, "window height in pixels")
+	flags.BoolVar(&opts.NoAudio, "no-audio", opts.NoAudio, "disable music playback")
+	flags.BoolVar(&opts.Fullscreen, "fullscreen", opts.Fullscreen, "start in fullscreen mode")
+	flags.IntVar(&opts.BallCount, "ball-count", opts.BallCount, fmt.Sprintf("number of 3D balls (1-%d)", maxBallCount))
+	flags.StringVar(&opts.ScrollText, "scroll-text", opts.ScrollText, "replace the main scroller text")
+	flags.StringVar(&opts.AssetZip, "assets", opts.AssetZip, "zip archive overriding the embedded assets")
+	flags.IntVar(&opts.SampleRate, "sample-rate", opts.SampleRate, "audio sample rate in Hz (0 = use the rate of the music file)")
+	flags.StringVar(&opts.IntroFont, "intro-font", opts.IntroFont, fmt.Sprintf("font of the intro scroller (%s)", strings.Join(fontNames, ", ")))
+	flags.StringVar(&opts.MainFont, "main-font", opts.MainFont, fmt.Sprintf("font of the main scroller (%s)", strings.Join(fontNames, ", ")))
+
+	if err := flags.Parse(args); err != nil {
+		return opts, err
+	}
+	if flags.NArg() > 0 {
+		return opts, fmt.Errorf("unexpected arguments: %v", flags.Args())
+	}
+	return opts, opts.validate()
 }
 
 func main() {
-	game := NewGame()
+	opts, err := parseOptions(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid options: %v\nrun with -h for usage\n", err)
+		os.Exit(2)
+	}
+
+	game := NewGame(opts)
+
+	if opts.AssetZip != "" {
+		if err := game.OpenAssetZip(opts.AssetZip); err != nil {
+			game.logger.Fatal(err)
+		}
+	}
 
 	if err := game.Init(); err != nil {
-		log.Fatal(err)
+		game.logger.Fatal(err)
 	}
 
-	ebiten.SetWindowSize(screenWidth, screenHeight)
-	ebiten.SetWindowTitle("TCB 3D DOC Demo - Go/Ebiten")
+	if opts.IntroFont != "" {
+		if err := game.SetIntroFont(opts.IntroFont); err != nil {
+			game.logger.Fatal(err)
+		}
+	}
+	if opts.MainFont != "" {
+		if err := game.SetMainFont(opts.MainFont); err != nil {
+			game.logger.Fatal(err)
+		}
+	}
 
-	if err := ebiten.RunGame(game); err != nil {
-		log.Fatal(err)
+	if err := game.Run(); err != nil {
+		game.logger.Fatal(err)
 	}
 }
@@ -0,0 +1,1352 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// gpu active les tests qui ont besoin d'un contexte graphique (lecture de
+// pixels, shaders) : ils tournent alors dans la boucle de jeu d'Ebiten
+var gpu = flag.Bool("gpu", false, "run the tests that need a graphics context")
+
+// gpuRunning passe à vrai quand les tests tournent dans la boucle de jeu
+var gpuRunning bool
+
+var errTestsDone = errors.New("tests done")
+
+// testRunner exécute tous les tests depuis le premier Update de la boucle
+type testRunner struct {
+	m    *testing.M
+	code int
+}
+
+func (r *testRunner) Update() error {
+	gpuRunning = true
+	r.code = r.m.Run()
+	return errTestsDone
+}
+
+func (r *testRunner) Draw(screen *ebiten.Image) {}
+
+func (r *testRunner) Layout(outsideWidth, outsideHeight int) (int, int) {
+	return screenWidth, screenHeight
+}
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+	if !*gpu {
+		os.Exit(m.Run())
+	}
+
+	r := &testRunner{m: m}
+	if err := ebiten.RunGame(r); err != nil && !errors.Is(err, errTestsDone) {
+		fmt.Fprintf(os.Stderr, "failed to start the game loop: %v\n", err)
+		os.Exit(1)
+	}
+	os.Exit(r.code)
+}
+
+// requireGPU ignore le test quand aucun contexte graphique n'est disponible
+func requireGPU(tb testing.TB) {
+	tb.Helper()
+	if !gpuRunning {
+		tb.Skip("no graphics context, run with -gpu")
+	}
+}
+
+func TestBackdropScale(t *testing.T) {
+	for _, w := range []int{10, 64, 768} {
+		img := ebiten.NewImage(w, 4)
+		got := backdropScale(img, screenWidth)
+		img.Dispose()
+
+		if want := float64(screenWidth) / float64(w); got != want {
+			t.Errorf("backdropScale(width %d) = %v, want %v", w, got, want)
+		}
+	}
+}
+
+// newTestGame crée un jeu sans audio, initialisé et fermé en fin de test
+func newTestGame(tb testing.TB) *Game {
+	tb.Helper()
+
+	opts := DefaultOptions()
+	opts.NoAudio = true
+	g := NewGame(opts)
+	g.SetLogger(nil)
+	g.SetSeed(1)
+	if err := g.Init(); err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() { g.Close() })
+	return g
+}
+
+func benchmarkChessboard(b *testing.B, shader bool) {
+	requireGPU(b)
+	g := newTestGame(b)
+	g.useShaderFloor = shader
+	g.xm, g.speed = 1, 1
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		g.updateChessboard()
+		g.drawChessboard()
+	}
+}
+
+func BenchmarkChessboardShader(b *testing.B) { benchmarkChessboard(b, true) }
+func BenchmarkChessboardQuads(b *testing.B)  { benchmarkChessboard(b, false) }
+
+func TestSpriteHalfSize(t *testing.T) {
+	for _, size := range [][2]int{{64, 64}, {96, 96}, {64, 16}, {63, 17}} {
+		img := ebiten.NewImage(size[0], size[1])
+		hw, hh := spriteHalfSize(img)
+		img.Dispose()
+
+		if hw != float64(size[0])/2 || hh != float64(size[1])/2 {
+			t.Errorf("spriteHalfSize(%dx%d) = (%v, %v), want (%v, %v)",
+				size[0], size[1], hw, hh, float64(size[0])/2, float64(size[1])/2)
+		}
+	}
+}
+
+func benchmarkIntro(b *testing.B, cached bool) {
+	requireGPU(b)
+	g := newTestGame(b)
+	g.cacheIntroScroll = cached
+	screen := ebiten.NewImage(g.width, g.height)
+	defer screen.Dispose()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		g.drawIntro(screen)
+	}
+}
+
+func BenchmarkIntroCached(b *testing.B)   { benchmarkIntro(b, true) }
+func BenchmarkIntroUncached(b *testing.B) { benchmarkIntro(b, false) }
+
+func TestScrollDirections(t *testing.T) {
+	const text = "ABCD"
+	const spacing = 10.0
+	length := float64(len(text)) * spacing
+
+	// Premier caractère visible après chaque pas d'une cellule
+	firstChars := func(speed float64) string {
+		scrollX := 0.0
+		seen := ""
+		for i := 0; i < 2*len(text); i++ {
+			first, offset := scrollPosition(scrollX, spacing)
+			if offset != 0 {
+				t.Fatalf("offset %v at scrollX %v, want 0", offset, scrollX)
+			}
+			seen += string(text[(first%len(text)+len(text))%len(text)])
+			scrollX = advanceScroll(scrollX, speed, length)
+			if scrollX < 0 || scrollX >= length {
+				t.Fatalf("scrollX %v out of [0, %v)", scrollX, length)
+			}
+		}
+		return seen
+	}
+
+	if got := firstChars(spacing); got != "ABCDABCD" {
+		t.Errorf("forward scroll shows %q, want %q", got, "ABCDABCD")
+	}
+	if got := firstChars(-spacing); got != "ADCBADCB" {
+		t.Errorf("reverse scroll shows %q, want %q", got, "ADCBADCB")
+	}
+}
+
+func TestScrollPositionNegative(t *testing.T) {
+	first, offset := scrollPosition(-5, 10)
+	if first != -1 || offset != 5 {
+		t.Errorf("scrollPosition(-5, 10) = (%d, %v), want (-1, 5)", first, offset)
+	}
+}
+
+func TestMeasureText(t *testing.T) {
+	f := &Font{CellWidth: fontWidth, CellHeight: fontHeight}
+	for _, tc := range []struct {
+		text string
+		want float64
+	}{
+		{"", 0},
+		{"A", fontWidth},
+		{"HELLO WORLD", 11 * fontWidth},
+	} {
+		if got := f.measureText(tc.text); got != tc.want {
+			t.Errorf("measureText(%q) = %v, want %v", tc.text, got, tc.want)
+		}
+	}
+}
+
+func TestBuiltinGlyphsOnlyWhenUsed(t *testing.T) {
+	g := NewGame(DefaultOptions())
+	g.registerBuiltinGlyphs("PLAIN TEXT")
+	g.EnqueueScrollText("NO SPRITE HERE")
+	if len(g.glyphs) != 0 {
+		t.Fatalf("%d glyphs registered for texts without sentinels", len(g.glyphs))
+	}
+
+	g.EnqueueScrollText("GREETINGS \x02 TO ALL")
+	if _, ok := g.glyphs[glyphHeart]; !ok || len(g.glyphs) != 1 {
+		t.Errorf("glyphs %v after a heart sentinel, want only the heart", g.glyphs)
+	}
+}
+
+func TestScrollStartWithGlyphs(t *testing.T) {
+	g := NewGame(DefaultOptions())
+	f := &Font{CellWidth: fontWidth, CellHeight: fontHeight}
+	if err := g.RegisterGlyph(glyphStar, ebiten.NewImage(20, 20)); err != nil {
+		t.Fatal(err)
+	}
+
+	// "A", étoile de 20 pixels, "B" : 144 pixels au total
+	const text = "A\x01B"
+	if got, want := g.textWidth(f, text), float64(2*fontWidth+20); got != want {
+		t.Errorf("textWidth = %v, want %v", got, want)
+	}
+	for _, c := range []struct {
+		scrollX float64
+		char    int
+		offset  float64
+	}{
+		{0, 0, 0},
+		{70, 1, 8},
+		{90, 2, 8},
+		{144 + 70, 1, 8}, // Tour suivant
+	} {
+		char, offset := g.scrollStart(f, text, c.scrollX)
+		if char != c.char || offset != c.offset {
+			t.Errorf("scrollStart(%v) = (%d, %v), want (%d, %v)", c.scrollX, char, offset, c.char, c.offset)
+		}
+	}
+}
+
+func TestProjectCenterYOffset(t *testing.T) {
+	cam := DefaultCamera()
+	cam.CenterYOffset = -20
+
+	// À Z = FocalLength l'échelle de projection vaut 1/2
+	s := cam.Project(Vec3{X: 0, Y: 50, Z: cam.FocalLength}, screenWidth, screenHeight)
+	if want := float64(screenHeight)/2 - 20 + 25; s.V != want {
+		t.Errorf("V = %v, want %v", s.V, want)
+	}
+	if want := float64(screenWidth) / 2; s.U != want {
+		t.Errorf("U = %v, want %v", s.U, want)
+	}
+
+	// Le décalage ne déplace que V
+	ref := DefaultCamera().Project(Vec3{X: 0, Y: 50, Z: cam.FocalLength}, screenWidth, screenHeight)
+	if d := ref.V - s.V; d != 60 {
+		t.Errorf("offset change moved V by %v, want 60", d)
+	}
+	if ref.U != s.U || ref.W != s.W {
+		t.Errorf("offset change moved U/W: %+v vs %+v", s, ref)
+	}
+}
+
+// blendedAnim reproduit le mélange de formes d'onde de drawDoc au temps t
+func blendedAnim(t float64, durations []float64, blendDuration float64, i int) Anim {
+	seg := segmentAt(t, durations)
+	alpha := blendAlpha(seg.Elapsed, blendDuration, seg.Duration)
+	return blendAnim(getMovement(seg.Index, t, i), getMovement(seg.Next, t, i), alpha)
+}
+
+func TestBlendContinuousAtBoundaries(t *testing.T) {
+	durations := make([]float64, 8)
+	for k := range durations {
+		durations[k] = animDuration
+	}
+	const eps = 1e-7
+
+	// Deux segments d'intro puis trois cycles complets, rebouclage 1 -> 2 compris
+	for b := 1; b <= 2+3*len(durations); b++ {
+		boundary := float64(b * animDuration)
+		before := blendedAnim(boundary-eps, durations, 1.25, 0)
+		after := blendedAnim(boundary+eps, durations, 1.25, 0)
+
+		for _, d := range []float64{
+			before.SpinSpeed - after.SpinSpeed,
+			before.Displace - after.Displace,
+			before.BallLineDisplacement - after.BallLineDisplacement,
+			before.RadiusFromCenterOfScreen - after.RadiusFromCenterOfScreen,
+		} {
+			if math.Abs(d) > 1e-3 {
+				t.Errorf("movement jumps at t=%v: %+v -> %+v", boundary, before, after)
+				break
+			}
+		}
+	}
+}
+
+func TestRotateAxisMatchesRotateY(t *testing.T) {
+	for _, r := range []float64{0, 0.3, math.Pi / 2, 2, math.Pi, -1.2, 5} {
+		want := Vec3{X: 150, Y: -40, Z: 25}
+		want.RotateY(r)
+
+		// Axe non normalisé : RotateAxis le normalise
+		got := Vec3{X: 150, Y: -40, Z: 25}
+		got.RotateAxis(Vec3{Y: 3}, r)
+
+		if math.Abs(got.X-want.X) > 1e-9 || math.Abs(got.Y-want.Y) > 1e-9 || math.Abs(got.Z-want.Z) > 1e-9 {
+			t.Errorf("angle %v: RotateAxis = %+v, RotateY = %+v", r, got, want)
+		}
+	}
+}
+
+func TestRotateAxisZeroAxis(t *testing.T) {
+	v := Vec3{X: 1, Y: 2, Z: 3}
+	v.RotateAxis(Vec3{}, 1)
+	if v != (Vec3{X: 1, Y: 2, Z: 3}) {
+		t.Errorf("zero axis changed the vector to %+v", v)
+	}
+}
+
+func TestDrawOrderExcludesNearPlane(t *testing.T) {
+	g := NewGame(DefaultOptions())
+	f := g.camera.FocalLength
+	balls := []Sprite{
+		{Z: 0},
+		{Z: -f}, // Sur l'observateur
+		{Z: 100},
+		{Z: -f + g.camera.NearPlane/2}, // Devant le plan proche
+		{Z: -f + g.camera.NearPlane*2},
+	}
+
+	got := g.drawOrder(balls)
+	want := []int{2, 0, 4}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("drawOrder = %v, want %v", got, want)
+	}
+}
+
+func TestGroundYShiftsShadow(t *testing.T) {
+	g := NewGame(DefaultOptions())
+	ball := Vec3{X: 80, Y: -30, Z: g.camera.FocalLength} // Échelle de projection 1/2
+
+	before := g.camera.Project(g.shadowPoint(ball), screenWidth, screenHeight)
+	g.groundY += 40
+	after := g.camera.Project(g.shadowPoint(ball), screenWidth, screenHeight)
+
+	if d := after.V - before.V; d != 20 {
+		t.Errorf("raising groundY by 40 moved the shadow V by %v, want 20", d)
+	}
+	if after.U != before.U || after.W != before.W {
+		t.Errorf("groundY changed U/W: %+v -> %+v", before, after)
+	}
+}
+
+// pinnedFrameHashes fige les hashes de FrameHash relevés sur une machine de
+// référence (voir le log de TestFrameHash) ; une image absente n'est pas vérifiée
+var pinnedFrameHashes = map[int]uint64{}
+
+func TestFrameHash(t *testing.T) {
+	requireGPU(t)
+
+	hashes := map[int]uint64{}
+	for _, frame := range []int{0, 60, 200} {
+		// Deux jeux neufs doivent produire exactement la même image
+		var got [2]uint64
+		for k := range got {
+			h, err := FrameHash(newTestGame(t), frame)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got[k] = h
+		}
+		if got[0] != got[1] {
+			t.Errorf("frame %d: hash %#x then %#x", frame, got[0], got[1])
+		}
+		hashes[frame] = got[0]
+
+		if want, ok := pinnedFrameHashes[frame]; ok && got[0] != want {
+			t.Errorf("frame %d: hash %#x, pinned %#x", frame, got[0], want)
+		}
+	}
+
+	if hashes[0] == hashes[60] || hashes[60] == hashes[200] {
+		t.Errorf("animation did not change the frame: %#x", hashes)
+	}
+	t.Logf("frame hashes: 0=%#x 60=%#x 200=%#x", hashes[0], hashes[60], hashes[200])
+}
+
+func benchmarkBall(b *testing.B, shader bool) {
+	requireGPU(b)
+	g := newTestGame(b)
+	screen := ebiten.NewImage(g.width, g.height)
+	defer screen.Dispose()
+
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(0.7, 0.7)
+	op.GeoM.Translate(300, 200)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if shader {
+			g.drawShadedBall(screen, g.sphere, op)
+		} else {
+			screen.DrawImage(g.sphere, op)
+		}
+	}
+}
+
+func BenchmarkBallShader(b *testing.B) { benchmarkBall(b, true) }
+func BenchmarkBallSprite(b *testing.B) { benchmarkBall(b, false) }
+
+// opaqueRows retourne la première et la dernière ligne de img contenant un
+// pixel non transparent (-1, -1 pour une image vide)
+func opaqueRows(img *ebiten.Image) (int, int) {
+	b := img.Bounds()
+	pix := make([]byte, 4*b.Dx()*b.Dy())
+	img.ReadPixels(pix)
+
+	first, last := -1, -1
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			if pix[4*(y*b.Dx()+x)+3] != 0 {
+				if first < 0 {
+					first = y
+				}
+				last = y
+				break
+			}
+		}
+	}
+	return first, last
+}
+
+func TestScrollerY(t *testing.T) {
+	requireGPU(t)
+	g := newTestGame(t)
+	g.text1 = strings.Repeat("HELLO ", 20)
+	g.text2 = strings.Repeat("WORLD ", 20)
+	g.introScrollY = 300
+	g.mainScrollY = 140
+
+	screen := ebiten.NewImage(g.width, g.height)
+	defer screen.Dispose()
+
+	g.drawIntro(screen)
+	first, last := opaqueRows(screen)
+	if first < 300 || last >= 300+fontHeight {
+		t.Errorf("intro scroller drawn on rows %d-%d, want within %d-%d", first, last, 300, 300+fontHeight-1)
+	}
+
+	// Le rebond du scroller principal le décale de 0 à 60 pixels vers le bas
+	screen.Clear()
+	g.drawScroller(screen)
+	first, last = opaqueRows(screen)
+	if first < 140 || last >= 140+120 {
+		t.Errorf("main scroller drawn on rows %d-%d, want within %d-%d", first, last, 140, 140+120-1)
+	}
+}
+
+func TestCachedIntroFollowsTextStyle(t *testing.T) {
+	requireGPU(t)
+	g := newTestGame(t)
+	text := strings.Repeat("HELLO ", 20)
+
+	cached := ebiten.NewImage(g.width, fontHeight+8)
+	defer cached.Dispose()
+	direct := ebiten.NewImage(g.width, fontHeight+8)
+	defer direct.Dispose()
+
+	pixels := func(img *ebiten.Image) []byte {
+		pix := make([]byte, 4*img.Bounds().Dx()*img.Bounds().Dy())
+		img.ReadPixels(pix)
+		return pix
+	}
+
+	// Même position à chaque fois : seul le style change la bande
+	g.drawCachedScrollText(cached, g.introFont, text, 0)
+	for _, style := range []struct{ shadow, outline bool }{{true, false}, {false, true}, {false, false}} {
+		g.scrollShadow, g.scrollOutline = style.shadow, style.outline
+
+		cached.Clear()
+		g.drawCachedScrollText(cached, g.introFont, text, 0)
+		direct.Clear()
+		g.drawScrollText(direct, g.introFont, text, 0)
+
+		if string(pixels(cached)) != string(pixels(direct)) {
+			t.Errorf("shadow %v, outline %v: cached strip differs from direct drawing", style.shadow, style.outline)
+		}
+	}
+}
+
+func TestEnqueueAfterFinishedQueue(t *testing.T) {
+	g := NewGame(DefaultOptions())
+	g.scrollQueueLoop = false
+
+	g.EnqueueScrollText("FIRST")
+	if g.scrollQueueIndex != 0 || !strings.Contains(g.text2, "FIRST") {
+		t.Fatalf("first message not started: index %d, text %q", g.scrollQueueIndex, g.text2)
+	}
+
+	// Le message sort de l'écran : la file non bouclée se termine
+	g.scrollX2 = g.scrollQueueEnd
+	g.advanceScrollQueue()
+	if g.scrollQueueIndex != -1 {
+		t.Fatalf("queue not finished: index %d", g.scrollQueueIndex)
+	}
+
+	g.EnqueueScrollText("SECOND")
+	if g.scrollQueueIndex != 1 || !strings.Contains(g.text2, "SECOND") {
+		t.Errorf("message enqueued after the end not started: index %d, text %q", g.scrollQueueIndex, g.text2)
+	}
+}
+
+func TestScrollQueueReverse(t *testing.T) {
+	g := NewGame(DefaultOptions())
+	g.scrollReverse = true
+	g.EnqueueScrollText("FIRST", "SECOND")
+
+	// Même avance que updateScroller, à l'envers
+	frames := 0
+	for g.scrollQueueIndex == 0 && frames < 10000 {
+		g.scrollX2 = advanceScroll(g.scrollX2, -3, float64(len(g.text2)*fontWidth))
+		g.advanceScrollQueue()
+		frames++
+	}
+
+	// Le message doit traverser toute la largeur du canvas avant le suivant
+	minFrames := (g.scrollCanvasWidth() + len("FIRST")*fontWidth) / 3
+	if g.scrollQueueIndex != 1 || frames < minFrames {
+		t.Errorf("reverse queue moved to index %d after %d frames, want 1 after at least %d", g.scrollQueueIndex, frames, minFrames)
+	}
+}
+
+func TestStateRoundTrip(t *testing.T) {
+	newQueued := func() *Game {
+		g := NewGame(DefaultOptions())
+		g.EnqueueScrollText("ONE", "TWO")
+		return g
+	}
+
+	g := newQueued()
+	g.vbl, g.vbl2, g.vbl3, g.vbl4 = 1.5, 2.5, 42, 3.5
+	g.xMove, g.yMove, g.xm, g.speed = 4, 5, 6, -0.75
+	g.mountainsX = 7
+	g.scrollX1, g.scrollX2, g.scrollX3 = 8, 9, 10
+	g.currentRadians, g.smoothedSpin, g.secondRingRadians = 1.1, -2.2, 3.3
+	g.animTime, g.jumpTime, g.outroTime = 12.5, 3.25, 11
+	g.phase = PhaseOutro
+	g.looped = true
+	g.scrollerPaused = true
+	g.layoutQueuedMessage(1)
+
+	data, err := g.MarshalState()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := newQueued()
+	if err := loaded.LoadState(data); err != nil {
+		t.Fatal(err)
+	}
+	again, err := loaded.MarshalState()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(again) != string(data) {
+		t.Errorf("state changed after a round trip:\n%s\n%s", data, again)
+	}
+	if loaded.text2 != g.text2 || loaded.scrollX2 != g.scrollX2 {
+		t.Errorf("queued message not restored: %q at %v, want %q at %v", loaded.text2, loaded.scrollX2, g.text2, g.scrollX2)
+	}
+}
+
+func TestStateRoundTripFrames(t *testing.T) {
+	requireGPU(t)
+	newQueued := func() *Game {
+		g := newTestGame(t)
+		g.EnqueueScrollText("ONE", "TWO")
+		return g
+	}
+
+	// État pris en pleine scène principale, après le saut
+	g := newQueued()
+	if _, err := FrameHash(g, 600); err != nil {
+		t.Fatal(err)
+	}
+	data, err := g.MarshalState()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := newQueued()
+	if err := loaded.LoadState(data); err != nil {
+		t.Fatal(err)
+	}
+
+	// Les deux jeux doivent ensuite produire exactement les mêmes images
+	for _, frames := range []int{0, 60, 200} {
+		want, err := FrameHash(g, frames)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := FrameHash(loaded, frames)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("after %d more ticks: loaded game hash %#x, original %#x", frames, got, want)
+		}
+	}
+}
+
+func TestStateHistoryStepBack(t *testing.T) {
+	g := NewGame(DefaultOptions())
+
+	// Plus d'états que l'historique n'en garde : les plus anciens sont oubliés
+	for i := 0; i < stateHistorySize+10; i++ {
+		g.animTime = float64(i)
+		g.recordState()
+	}
+
+	for want := stateHistorySize + 8; want >= 10; want-- {
+		if !g.restorePreviousState() {
+			t.Fatalf("history exhausted before animTime %d", want)
+		}
+		if g.animTime != float64(want) {
+			t.Fatalf("stepped back to animTime %v, want %d", g.animTime, want)
+		}
+		// Le tick rejoué enregistre de nouveau l'état restauré
+		g.recordState()
+	}
+}
+
+func TestMusicLoopVolume(t *testing.T) {
+	g := NewGame(DefaultOptions())
+	g.loopDuration, g.musicLoopFade = 60, 2
+
+	for _, c := range []struct {
+		animTime float64
+		looped   bool
+		want     float64
+	}{
+		{30, false, 1},
+		{59, false, 0.5}, // Descente avant la fin de la boucle
+		{1, true, 0.5},   // Remontée après le redémarrage
+		{1, false, 1},    // Premier passage : pas de remontée
+	} {
+		g.animTime, g.looped = c.animTime, c.looped
+		if got := g.musicLoopVolume(); got != c.want {
+			t.Errorf("animTime %v, looped %v: volume %v, want %v", c.animTime, c.looped, got, c.want)
+		}
+	}
+
+	// L'outro dure jusqu'à la fermeture : la musique ne s'éteint pas
+	g.outro = true
+	for _, at := range []float64{59, 60, 75} {
+		g.animTime = at
+		if at >= g.loopDuration {
+			g.setPhase(PhaseOutro)
+		}
+		if got := g.musicLoopVolume(); got != 1 {
+			t.Errorf("outro at animTime %v: volume %v, want 1", at, got)
+		}
+	}
+}
+
+func TestCloseReleasesPauseState(t *testing.T) {
+	g := NewGame(DefaultOptions())
+	g.pauseFrame = ebiten.NewImage(4, 4)
+	g.recordState()
+
+	if err := g.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if g.pauseFrame != nil || g.stateHistory != nil {
+		t.Errorf("Close kept pauseFrame %v, state history of %d entries", g.pauseFrame, len(g.stateHistory))
+	}
+}
+
+func TestBrightnessScale(t *testing.T) {
+	g := NewGame(DefaultOptions())
+	for _, tc := range []struct{ set, want float64 }{
+		{1, 1},
+		{0.5, 0.5},
+		{1.8, 1.8},
+		{-2, 0}, // Une luminosité négative est ramenée à 0
+	} {
+		g.SetBrightness(tc.set)
+		cs := g.brightnessScale()
+		want := float32(tc.want)
+		if cs.R() != want || cs.G() != want || cs.B() != want || cs.A() != 1 {
+			t.Errorf("SetBrightness(%v): ColorScale %v, want RGB %v and alpha 1", tc.set, cs, want)
+		}
+	}
+}
+
+func TestProjectWTracksSpriteScale(t *testing.T) {
+	cam := DefaultCamera()
+	for _, scale := range []float64{0.7, 1, 1.5, 0.25} {
+		cam.SpriteScale = scale
+
+		if s := cam.Project(Vec3{}, screenWidth, screenHeight); s.W != scale {
+			t.Errorf("SpriteScale %v: W at Z=0 is %v, want %v", scale, s.W, scale)
+		}
+		if s := cam.Project(Vec3{Z: cam.FocalLength}, screenWidth, screenHeight); s.W != scale/2 {
+			t.Errorf("SpriteScale %v: W at Z=FocalLength is %v, want %v", scale, s.W, scale/2)
+		}
+	}
+}
+
+func TestFogFactorIgnoresSpriteScale(t *testing.T) {
+	cam := DefaultCamera()
+	for _, scale := range []float64{0.7, 1, 1.5} {
+		cam.SpriteScale = scale
+
+		for _, c := range []struct {
+			z    float64
+			want float32
+		}{
+			{-100, 1},                  // Devant le plan Z=0 : pas de brouillard
+			{0, 1},                     // Plan Z=0
+			{cam.FocalLength, 0.6},     // Brouillard complet
+			{cam.FocalLength * 3, 0.6}, // Borné au-delà
+		} {
+			w := cam.Project(Vec3{Z: c.z}, screenWidth, screenHeight).W
+			if got := cam.fogFactor(w, 0.4); math.Abs(float64(got-c.want)) > 1e-6 {
+				t.Errorf("SpriteScale %v, Z %v: fog %v, want %v", scale, c.z, got, c.want)
+			}
+		}
+	}
+}
+
+// newIntroGame crée un jeu sans assets dont l'intro fait défiler text avec
+// une font factice de la taille des vraies
+func newIntroGame(text string) *Game {
+	g := NewGame(DefaultOptions())
+	g.font1 = &Font{CellWidth: fontWidth, CellHeight: fontHeight}
+	g.text1 = text
+	g.introTimeout = 0
+	return g
+}
+
+func TestIntroSentinelJump(t *testing.T) {
+	for _, text := range []string{`\ABCD`, `AB\CD`, `ABCDEFG\`} {
+		g := newIntroGame(text)
+		want := strings.IndexByte(text, '\\')
+
+		jumped := false
+		for i := 0; i < 1000 && !jumped; i++ {
+			before := g.scrollX1
+			g.tick()
+			if g.phase == PhaseMain {
+				jumped = true
+				if got := int(before / fontWidth); got != want {
+					t.Errorf("%q: jumped on character %d, want %d", text, got, want)
+				}
+			}
+		}
+		if !jumped {
+			t.Errorf("%q: no jump", text)
+		}
+	}
+}
+
+func TestIntroWithoutSentinel(t *testing.T) {
+	g := newIntroGame("NO SENTINEL HERE")
+
+	// Plusieurs tours complets du texte
+	for i := 0; i < 2000; i++ {
+		g.tick()
+		if g.phase != PhaseIntro {
+			t.Fatalf("jumped at tick %d without sentinel", i)
+		}
+	}
+}
+
+func TestWindowDefaults(t *testing.T) {
+	opts := DefaultOptions()
+	if opts.WindowWidth != 768 || opts.WindowHeight != 540 {
+		t.Errorf("DefaultOptions window = %dx%d, want 768x540", opts.WindowWidth, opts.WindowHeight)
+	}
+
+	g := NewGame(opts)
+	if g.WindowTitle != "TCB 3D DOC Demo - Go/Ebiten" {
+		t.Errorf("WindowTitle = %q", g.WindowTitle)
+	}
+	if g.WindowWidth != 768 || g.WindowHeight != 540 {
+		t.Errorf("Game window = %dx%d, want 768x540", g.WindowWidth, g.WindowHeight)
+	}
+
+	// Une taille invalide retombe sur la taille par défaut
+	opts.WindowWidth = 0
+	g = NewGame(opts)
+	if g.WindowWidth != 768 || g.WindowHeight != 540 {
+		t.Errorf("fallback window = %dx%d, want 768x540", g.WindowWidth, g.WindowHeight)
+	}
+}
+
+func benchmarkBlur(b *testing.B, radius int) {
+	requireGPU(b)
+	g := newTestGame(b)
+	src := ebiten.NewImage(g.width, g.height)
+	defer src.Dispose()
+	g.drawBackdrop(src)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		g.blur(src, radius)
+	}
+}
+
+func BenchmarkBlurRadius4(b *testing.B)   { benchmarkBlur(b, 4) }
+func BenchmarkBlurRadiusMax(b *testing.B) { benchmarkBlur(b, maxBlurRadius) }
+
+func TestSegmentAtBoundaries(t *testing.T) {
+	// Durées distinctes et exactes : la forme k dure k+1 secondes
+	durations := []float64{1, 2, 3, 4, 5, 6, 7, 8}
+
+	// Intro : 7 -> 7 -> 2 sur [0, 16), puis cycle de 36 s sur 2..7, 0, 1
+	for _, c := range []struct {
+		t       float64
+		index   int
+		next    int
+		elapsed float64
+	}{
+		{0, 7, 7, 0},
+		{7.5, 7, 7, 7.5},
+		{8, 7, 2, 0},
+		{15.5, 7, 2, 7.5},
+		{16, 2, 3, 0},
+		{18.5, 2, 3, 2.5},
+		{19, 3, 4, 0},
+		{23, 4, 5, 0},
+		{28, 5, 6, 0},
+		{34, 6, 7, 0},
+		{41, 7, 0, 0},
+		{48.5, 7, 0, 7.5},
+		{49, 0, 1, 0},
+		{50, 1, 2, 0},
+		{51.5, 1, 2, 1.5},
+		{52, 2, 3, 0},
+		{52 + 36, 2, 3, 0},
+		{50 + 36, 1, 2, 0},
+	} {
+		seg := segmentAt(c.t, durations)
+		if seg.Index != c.index || seg.Next != c.next || seg.Elapsed != c.elapsed || seg.Duration != durations[c.index] {
+			t.Errorf("segmentAt(%v) = %+v, want index %d next %d elapsed %v", c.t, seg, c.index, c.next, c.elapsed)
+		}
+	}
+}
+
+func TestPrecalcScrollX(t *testing.T) {
+	for _, c := range []struct {
+		amplitude float64
+		symmetric bool
+	}{
+		{1, false},
+		{2.5, false},
+		{1, true},
+	} {
+		g := NewGame(DefaultOptions())
+		g.scrollWaveAmplitude = c.amplitude
+		g.symmetricWave = c.symmetric
+		g.precalcScrollX()
+
+		if len(g.scrollX) != 1035 || g.scrollXMod != 1035 {
+			t.Errorf("%+v: %d entries, scrollXMod %d, want 1035", c, len(g.scrollX), g.scrollXMod)
+		}
+
+		// 20·sin + 30·cos ne dépasse pas 50 ; extrema pinnés de la table d'origine
+		lo, hi := g.scrollX[0], g.scrollX[0]
+		for _, x := range g.scrollX {
+			lo, hi = min(lo, x), max(hi, x)
+		}
+		want := 49.384417029756889 * c.amplitude
+		if math.Abs(lo+want) > 1e-9 || math.Abs(hi-want) > 1e-9 {
+			t.Errorf("%+v: range [%v, %v], want ±%v", c, lo, hi, want)
+		}
+		if hi > 50*c.amplitude || lo < -50*c.amplitude {
+			t.Errorf("%+v: range [%v, %v] exceeds ±%v", c, lo, hi, 50*c.amplitude)
+		}
+	}
+}
+
+func TestTimelineSweep(t *testing.T) {
+	g := newTestGame(t)
+	g.introTimeout = 0
+	dst := ebiten.NewImage(g.width, g.height)
+	defer dst.Dispose()
+
+	finite := func(tick int) {
+		t.Helper()
+		for name, v := range map[string]float64{
+			"vbl": g.vbl, "vbl4": g.vbl4, "xMove": g.xMove, "yMove": g.yMove, "speed": g.speed,
+			"scrollX1": g.scrollX1, "scrollX2": g.scrollX2, "currentRadians": g.currentRadians,
+		} {
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				t.Fatalf("tick %d: %s = %v", tick, name, v)
+			}
+		}
+	}
+
+	// Intro jusqu'au caractère '\'
+	tick := 0
+	for ; g.phase == PhaseIntro; tick++ {
+		if tick > 100000 {
+			t.Fatal("intro never reached the sentinel")
+		}
+		g.tick()
+		g.DrawTo(dst)
+		finite(tick)
+	}
+	if g.phase != PhaseMain {
+		t.Fatalf("phase = %v after the intro, want PhaseMain", g.phase)
+	}
+	if g.jumpTime != g.animTime {
+		t.Errorf("jumpTime = %v, want animTime %v", g.jumpTime, g.animTime)
+	}
+
+	// Les deux segments d'intro et un cycle complet de formes d'onde
+	end := tick + (2+len(g.animDurations))*animDuration*ebiten.TPS()
+	for ; tick < end; tick++ {
+		g.tick()
+		g.DrawTo(dst)
+		finite(tick)
+	}
+}
+
+func TestDrawHasNoSideEffects(t *testing.T) {
+	// Une image sautée (fenêtre réduite) ou redessinée (pause) ne doit rien
+	// décaler : seul Update fait avancer l'animation
+	g := newTestGame(t)
+	g.EnqueueScrollText("ONE", "TWO")
+	g.secondRing = true
+	g.spinSmoothing = 0.5
+	dst := ebiten.NewImage(g.width, g.height)
+	defer dst.Dispose()
+
+	for _, phase := range []Phase{PhaseIntro, PhaseMain, PhaseOutro} {
+		g.setPhase(phase)
+		for i := 0; i < 30; i++ {
+			g.tick()
+		}
+
+		before, err := g.MarshalState()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 3; i++ {
+			g.DrawTo(dst)
+		}
+		after, err := g.MarshalState()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(after) != string(before) {
+			t.Errorf("phase %v: drawing changed the state:\n%s\n%s", phase, before, after)
+		}
+	}
+}
+
+// crossedQuadAlpha dessine un quadrilatère croisé semi-transparent de 64x64
+// avec rule et retourne l'alpha des points de chaque zone : recouvrement des
+// deux triangles, premier triangle seul, second seul, hors du quadrilatère.
+// Le rendu passe par le GPU avec drawQuad ou par rasterQuad avec software.
+func crossedQuadAlpha(rule ebiten.FillRule, software bool) [4]uint8 {
+	c := color.RGBA{255, 255, 255, 128}
+
+	var at func(x, y int) color.Color
+	if software {
+		img := image.NewRGBA(image.Rect(0, 0, 64, 64))
+		rasterQuad(img, 0, 0, 64, 0, 0, 64, 64, 64, c, c, rule)
+		at = img.At
+	} else {
+		img := ebiten.NewImage(64, 64)
+		defer img.Dispose()
+		drawQuad(img, 0, 0, 64, 0, 0, 64, 64, 64, c, c, rule)
+		at = img.At
+	}
+
+	var alpha [4]uint8
+	for k, p := range [][2]int{{8, 32}, {32, 8}, {32, 56}, {56, 32}} {
+		_, _, _, a := at(p[0], p[1]).RGBA()
+		alpha[k] = uint8(a >> 8)
+	}
+	return alpha
+}
+
+func TestDrawQuadFillRule(t *testing.T) {
+	// Les deux triangles du quadrilatère croisé se recouvrent avec des
+	// orientations opposées : seul FillAll dessine deux fois le recouvrement
+	for _, c := range []struct {
+		rule ebiten.FillRule
+		want [4]uint8
+	}{
+		{ebiten.FillAll, [4]uint8{192, 128, 128, 0}},
+		{ebiten.NonZero, [4]uint8{0, 128, 128, 0}},
+		{ebiten.EvenOdd, [4]uint8{0, 128, 128, 0}},
+	} {
+		// Le rendu logiciel tourne partout, le GPU seulement avec -gpu
+		renderers := []bool{true}
+		if gpuRunning {
+			renderers = append(renderers, false)
+		}
+		for _, software := range renderers {
+			got := crossedQuadAlpha(c.rule, software)
+			for k := range got {
+				if d := int(got[k]) - int(c.want[k]); d < -2 || d > 2 {
+					t.Errorf("rule %d, software %v: alpha %v, want %v", c.rule, software, got, c.want)
+					break
+				}
+			}
+		}
+	}
+}
+
+func TestRasterTrianglesSharedEdge(t *testing.T) {
+	// Les deux triangles d'un carré partagent une diagonale qui passe par des
+	// centres de pixels : chacun ne doit être dessiné qu'une fois
+	img := image.NewRGBA(image.Rect(0, 0, 16, 16))
+	c := color.RGBA{255, 255, 255, 128}
+	rasterQuad(img, 0.5, 0.5, 15.5, 0.5, 15.5, 15.5, 0.5, 15.5, c, c, ebiten.FillAll)
+
+	for y := 1; y < 15; y++ {
+		for x := 1; x < 15; x++ {
+			if a := img.RGBAAt(x, y).A; a != 128 {
+				t.Fatalf("pixel (%d, %d): alpha %d, want 128", x, y, a)
+			}
+		}
+	}
+}
+
+func TestRasterImageFloorPlacement(t *testing.T) {
+	g := NewGame(DefaultOptions())
+	src := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	draw.Draw(src, src.Bounds(), image.White, image.Point{}, draw.Src)
+
+	// Échelle 0.6 x 2.6 puis décalage de 260 : colonnes 0 à 5, lignes 260 à 285
+	dst := image.NewRGBA(image.Rect(0, 0, screenWidth, screenHeight))
+	rasterImage(dst, src, g.floorGeoM())
+
+	got := dst.Bounds()
+	got.Max = got.Min
+	first := true
+	for y := 0; y < screenHeight; y++ {
+		for x := 0; x < screenWidth; x++ {
+			if dst.RGBAAt(x, y).A == 0 {
+				continue
+			}
+			p := image.Rect(x, y, x+1, y+1)
+			if first {
+				got, first = p, false
+			} else {
+				got = got.Union(p)
+			}
+		}
+	}
+	if want := image.Rect(0, 260, 6, 286); got != want {
+		t.Errorf("floor covers %v, want %v", got, want)
+	}
+}
+
+func TestPremultiplied(t *testing.T) {
+	// Image décalée pour vérifier que l'origine est ramenée en (0, 0)
+	src := image.NewNRGBA(image.Rect(10, 20, 12, 21))
+	src.SetNRGBA(10, 20, color.NRGBA{200, 100, 50, 128})
+	src.SetNRGBA(11, 20, color.NRGBA{255, 255, 255, 255})
+
+	got := premultiplied(src)
+	if got.Bounds() != image.Rect(0, 0, 2, 1) {
+		t.Fatalf("bounds = %v, want (0,0)-(2,1)", got.Bounds())
+	}
+	if c := got.RGBAAt(0, 0); c != (color.RGBA{100, 50, 25, 128}) {
+		t.Errorf("semi-transparent pixel = %v, want {100 50 25 128}", c)
+	}
+	if c := got.RGBAAt(1, 0); c != (color.RGBA{255, 255, 255, 255}) {
+		t.Errorf("opaque pixel = %v, want white", c)
+	}
+
+	// Une image déjà prémultipliée est retournée telle quelle
+	if premultiplied(got) != got {
+		t.Error("*image.RGBA was copied")
+	}
+}
+
+func TestIntroToMainTransition(t *testing.T) {
+	g := newIntroGame(`AB\CD`)
+
+	for i := 0; g.phase == PhaseIntro; i++ {
+		if i > 1000 {
+			t.Fatal("intro never reached the sentinel")
+		}
+		if g.vbl != 0 || g.vbl2 != 0 {
+			t.Fatalf("main animation ran during the intro: vbl %v, vbl2 %v", g.vbl, g.vbl2)
+		}
+		g.tick()
+	}
+	if g.phase != PhaseMain {
+		t.Fatalf("phase = %v, want PhaseMain", g.phase)
+	}
+	if g.jumpTime != g.animTime {
+		t.Errorf("jumpTime = %v, want animTime %v", g.jumpTime, g.animTime)
+	}
+
+	// Les ticks suivants font tourner updateMain et non plus updateIntro
+	scrollX1 := g.scrollX1
+	for i := 0; i < 10; i++ {
+		g.tick()
+	}
+	if g.phase != PhaseMain {
+		t.Errorf("phase = %v after the jump, want PhaseMain", g.phase)
+	}
+	if g.scrollX1 != scrollX1 {
+		t.Errorf("intro kept scrolling: scrollX1 %v -> %v", scrollX1, g.scrollX1)
+	}
+	if math.Abs(g.vbl-10*0.16) > 1e-9 || math.Abs(g.vbl2-10*0.8) > 1e-9 {
+		t.Errorf("vbl = %v, vbl2 = %v after 10 main ticks", g.vbl, g.vbl2)
+	}
+}
+
+func TestDrawOrderTies(t *testing.T) {
+	g := NewGame(DefaultOptions())
+	balls := []Sprite{
+		{Z: 50},
+		{Z: 100},
+		{Z: 50},
+		{Z: 50 + 1e-9}, // Presque à égalité : garde l'ordre des indices
+		{Z: 100},
+	}
+
+	want := []int{1, 4, 0, 2, 3}
+	for i := 0; i < 10; i++ {
+		if got := g.drawOrder(balls); fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Fatalf("drawOrder = %v, want %v", got, want)
+		}
+	}
+
+	// Chaîne de profondeurs proches : chaque voisin est à moins d'un pas de
+	// l'autre, mais les extrêmes tombent dans des pas différents
+	tests := []struct {
+		zs   []float64
+		want []int
+	}{
+		{[]float64{50, 50 + 4e-7, 50 + 8e-7}, []int{2, 0, 1}},
+		{[]float64{50 + 8e-7, 50 + 4e-7, 50}, []int{0, 1, 2}},
+		{[]float64{50 + 4e-7, 50 + 8e-7, 50}, []int{1, 0, 2}},
+		{[]float64{-20 - 4e-7, -20, -20 + 4e-7}, []int{0, 1, 2}},
+	}
+	for _, tt := range tests {
+		balls := make([]Sprite, len(tt.zs))
+		for i, z := range tt.zs {
+			balls[i].Z = z
+		}
+		if got := g.drawOrder(balls); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("drawOrder(%v) = %v, want %v", tt.zs, got, tt.want)
+		}
+	}
+}
+
+func TestRestartRewindsScrollQueue(t *testing.T) {
+	g := NewGame(DefaultOptions())
+	g.scrollQueueLoop = false
+	g.EnqueueScrollText("FIRST", "SECOND")
+
+	// File terminée après ses deux messages
+	for i := 0; i < 2; i++ {
+		g.scrollX2 = g.scrollQueueEnd
+		g.advanceScrollQueue()
+	}
+	if g.scrollQueueIndex != -1 {
+		t.Fatalf("queue not finished: index %d", g.scrollQueueIndex)
+	}
+
+	g.restart()
+	if g.scrollQueueIndex != 0 || !strings.Contains(g.text2, "FIRST") || g.scrollX2 != 0 {
+		t.Errorf("restart left index %d, text %q, scrollX2 %v", g.scrollQueueIndex, g.text2, g.scrollX2)
+	}
+}
+
+func TestRestartResetsMountains(t *testing.T) {
+	g := NewGame(DefaultOptions())
+	g.mountainsX = 123
+
+	g.restart()
+	if g.mountainsX != 0 {
+		t.Errorf("restart left mountainsX %v, want 0", g.mountainsX)
+	}
+}
+
+func TestSmoothSpin(t *testing.T) {
+	for _, c := range []struct {
+		prev, target, smoothing, want float64
+	}{
+		{-5, 5, 0, 5},       // Sans lissage : la cible directement
+		{-5, 5, 0.5, 0},     // Mi-chemin
+		{-5, 5, 0.75, -2.5}, // Un quart du chemin
+		{-5, 5, -1, 5},      // Lissage négatif ramené à 0
+		{-5, 5, 1, -4.99},   // Lissage borné à 0.999 : la vitesse bouge encore
+		{3, 3, 0.9, 3},      // Cible atteinte : point fixe
+	} {
+		if got := smoothSpin(c.prev, c.target, c.smoothing); math.Abs(got-c.want) > 1e-9 {
+			t.Errorf("smoothSpin(%v, %v, %v) = %v, want %v", c.prev, c.target, c.smoothing, got, c.want)
+		}
+	}
+
+	// Convergence géométrique vers la cible : l'écart est multiplié par le
+	// lissage à chaque image et ne change jamais de signe
+	v := -7.0
+	for i := 1; i <= 100; i++ {
+		v = smoothSpin(v, 8, 0.9)
+		want := 8 - 15*math.Pow(0.9, float64(i))
+		if math.Abs(v-want) > 1e-9 {
+			t.Fatalf("frame %d: %v, want %v", i, v, want)
+		}
+	}
+}
+
+func TestAccumulateRadiansBounded(t *testing.T) {
+	// Un reste négatif infime ne doit pas donner 2π une fois ramené
+	if got := accumulateRadians(-1e-17, 0); got < 0 || got >= 2*math.Pi {
+		t.Errorf("accumulateRadians(-1e-17, 0) = %v, want within [0, 2π)", got)
+	}
+
+	for _, speed := range []float64{5, -5, -7.3, 8, -1e-9, 1e6, -1e6} {
+		radians := 0.0
+		for i := 0; i < 10000; i++ {
+			radians = accumulateRadians(radians, speed)
+			if radians < 0 || radians >= 2*math.Pi || math.IsNaN(radians) {
+				t.Fatalf("speed %v, frame %d: radians = %v, want within [0, 2π)", speed, i, radians)
+			}
+		}
+	}
+
+	// Une vitesse négative tourne dans l'autre sens
+	if got, want := accumulateRadians(0, -5), 2*math.Pi-(2*math.Pi/360)*5*0.15; math.Abs(got-want) > 1e-12 {
+		t.Errorf("accumulateRadians(0, -5) = %v, want %v", got, want)
+	}
+}
+
+func TestFloorGeoM(t *testing.T) {
+	g := NewGame(DefaultOptions())
+	check := func(name string, a, b, c, d, tx, ty float64) {
+		t.Helper()
+		m := g.floorGeoM()
+		ga, gb, gc, gd, gtx, gty := m.Element(0, 0), m.Element(0, 1), m.Element(1, 0), m.Element(1, 1), m.Element(0, 2), m.Element(1, 2)
+		if math.Abs(ga-a) > 1e-12 || gb != b || gc != c || math.Abs(gd-d) > 1e-12 || gtx != tx || gty != ty {
+			t.Errorf("%s: GeoM = [%v %v %v; %v %v %v], want [%v %v %v; %v %v %v]",
+				name, ga, gb, gtx, gc, gd, gty, a, b, tx, c, d, ty)
+		}
+	}
+
+	// Placement d'origine
+	check("default", 0.6, 0, 0, 2.6, 0, 260)
+
+	g.SetFloorPlacement(0.8, 3, 300)
+	check("placement", 0.8, 0, 0, 3, 0, 300)
+
+	// L'échelle horizontale suit la largeur de rendu
+	g.width = 2 * screenWidth
+	check("double width", 1.6, 0, 0, 3, 0, 300)
+
+	// La position et l'échelle verticales suivent la hauteur de rendu
+	g.SetResolution(screenWidth, screenHeight/2)
+	check("half height", 0.8, 0, 0, 1.5, 0, 150)
+}
+
+func TestProjectFollowsHeight(t *testing.T) {
+	g := NewGame(DefaultOptions())
+	ground := g.shadowPoint(Vec3{})
+	want := g.camera.Project(ground, screenWidth, screenHeight)
+
+	// 384x270 : même composition à mi-hauteur, le sol reste à l'écran
+	g.SetResolution(384, 270)
+	got := g.project(g.camera, ground)
+	if got.V != want.V/2 || got.V >= float64(g.height) {
+		t.Errorf("ground projected at V = %v, want %v", got.V, want.V/2)
+	}
+	if got.U != 192 || got.W != want.W {
+		t.Errorf("ground projected at U = %v, W = %v, want 192, %v", got.U, got.W, want.W)
+	}
+}
+
+func TestShadowLiftAt(t *testing.T) {
+	for _, c := range []struct {
+		w, curve, want float64
+	}{
+		{1, 1, 0},     // Au premier plan : aucun décalage
+		{1.3, 1, 0},   // W > 1 borné
+		{0, 1, 26},    // Au fond : décalage complet
+		{-0.5, 1, 26}, // W < 0 borné
+		{0.5, 1, 13},  // Montée linéaire d'origine
+		{0.5, 2, 6.5}, // Exposant 2 : montée retardée
+		{0.5, 0.5, 26 * math.Sqrt(0.5)},
+		{0.5, 0, 13}, // Exposant invalide : linéaire
+		{0, 3, 26},   // Les extrémités ne dépendent pas de l'exposant
+		{1, 3, 0},
+	} {
+		if got := shadowLiftAt(c.w, 26, c.curve); math.Abs(got-c.want) > 1e-12 {
+			t.Errorf("shadowLiftAt(%v, 26, %v) = %v, want %v", c.w, c.curve, got, c.want)
+		}
+	}
+}
+
+func TestSpinMultiplier(t *testing.T) {
+	// Rotation accumulée par updateRing en une image, partant de π pour ne
+	// pas reboucler
+	spin := func(m float64) float64 {
+		g := newTestGame(t)
+		g.setPhase(PhaseMain)
+		g.animTime = 30
+		g.SetSpinMultiplier(m)
+		g.currentRadians = math.Pi
+
+		g.updateRing()
+		return g.currentRadians - math.Pi
+	}
+
+	base := spin(1)
+	if base == 0 {
+		t.Fatal("ring did not rotate")
+	}
+	for _, m := range []float64{0.5, 2} {
+		if got := spin(m); math.Abs(got-m*base) > 1e-9 {
+			t.Errorf("multiplier %v: rotation %v, want %v", m, got, m*base)
+		}
+	}
+
+	// Bornes de SetSpinMultiplier
+	g := NewGame(DefaultOptions())
+	for _, c := range []struct{ m, want float64 }{
+		{10, maxSpinMultiplier},
+		{0, minSpinMultiplier},
+		{-2, minSpinMultiplier},
+		{1.5, 1.5},
+	} {
+		g.SetSpinMultiplier(c.m)
+		if g.spinMultiplier != c.want {
+			t.Errorf("SetSpinMultiplier(%v) = %v, want %v", c.m, g.spinMultiplier, c.want)
+		}
+	}
+}
+
+func TestFloorCompositeMode(t *testing.T) {
+	g := NewGame(DefaultOptions())
+	if got := g.floorMaskOptions().CompositeMode; got != ebiten.CompositeModeXor {
+		t.Errorf("default mask composite mode = %v, want CompositeModeXor", got)
+	}
+
+	for _, mode := range []ebiten.CompositeMode{ebiten.CompositeModeMultiply, ebiten.CompositeModeSourceOver} {
+		g.SetFloorCompositeMode(mode)
+		if got := g.floorMaskOptions().CompositeMode; got != mode {
+			t.Errorf("mask composite mode = %v, want %v", got, mode)
+		}
+	}
+}
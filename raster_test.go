@@ -0,0 +1,176 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Rendu logiciel
+//
+// Sans contexte graphique (tests headless), les pixels d'une *ebiten.Image ne
+// peuvent pas être relus. rasterQuad et rasterImage reproduisent sur le CPU,
+// dans une *image.RGBA, les deux opérations dont les tests vérifient le
+// résultat : le remplissage de drawQuad et la copie d'une image par une GeoM.
+// Ce n'est pas un moteur de rendu complet : filtre nearest et mélange
+// source-over uniquement.
+
+// rasterQuad est l'équivalent logiciel de drawQuad
+func rasterQuad(dst *image.RGBA, x1, y1, x2, y2, x3, y3, x4, y4 float64, c1, c2 color.RGBA, rule ebiten.FillRule) {
+	vertices, indices := quadVertices(x1, y1, x2, y2, x3, y3, x4, y4, c1, c2)
+	rasterTriangles(dst, vertices, indices, rule)
+}
+
+// rasterTriangles est l'équivalent logiciel de DrawTriangles avec une source
+// blanche unie. Avec NonZero et EvenOdd, seuls les pixels retenus par la règle
+// sont dessinés, chaque triangle qui les couvre y étant mélangé comme avec
+// FillAll, à l'image du test de stencil d'ebiten.
+func rasterTriangles(dst *image.RGBA, vertices []ebiten.Vertex, indices []uint16, rule ebiten.FillRule) {
+	b := dst.Bounds()
+	triangle := func(t int) [3]ebiten.Vertex {
+		return [3]ebiten.Vertex{vertices[indices[t]], vertices[indices[t+1]], vertices[indices[t+2]]}
+	}
+
+	// Enroulement de chaque pixel : somme des orientations pour NonZero,
+	// nombre de triangles pour EvenOdd
+	var winding []int
+	if rule != ebiten.FillAll {
+		winding = make([]int, b.Dx()*b.Dy())
+		for t := 0; t+2 < len(indices); t += 3 {
+			v := triangle(t)
+			sign := orientation(v)
+			if rule == ebiten.EvenOdd {
+				sign = 1
+			}
+			rasterTriangle(b, v, func(x, y int, _ [3]float64) {
+				winding[(y-b.Min.Y)*b.Dx()+x-b.Min.X] += sign
+			})
+		}
+	}
+
+	for t := 0; t+2 < len(indices); t += 3 {
+		v := triangle(t)
+		rasterTriangle(b, v, func(x, y int, l [3]float64) {
+			if winding != nil {
+				w := winding[(y-b.Min.Y)*b.Dx()+x-b.Min.X]
+				if w == 0 || (rule == ebiten.EvenOdd && w%2 == 0) {
+					return
+				}
+			}
+
+			// Couleurs des sommets prémultipliées puis interpolées
+			var c [4]float64
+			for k := range v {
+				a := float64(v[k].ColorA)
+				c[0] += l[k] * float64(v[k].ColorR) * a
+				c[1] += l[k] * float64(v[k].ColorG) * a
+				c[2] += l[k] * float64(v[k].ColorB) * a
+				c[3] += l[k] * a
+			}
+			blendPixel(dst, x, y, c)
+		})
+	}
+}
+
+// orientation retourne le sens de parcours du triangle v : 1, -1 ou 0 s'il
+// est dégénéré
+func orientation(v [3]ebiten.Vertex) int {
+	area := (v[1].DstX-v[0].DstX)*(v[2].DstY-v[0].DstY) - (v[1].DstY-v[0].DstY)*(v[2].DstX-v[0].DstX)
+	switch {
+	case area > 0:
+		return 1
+	case area < 0:
+		return -1
+	}
+	return 0
+}
+
+// rasterTriangle appelle fn pour chaque pixel de bounds dont le centre est
+// dans le triangle v, avec ses coordonnées barycentriques. Un centre posé sur
+// une arête partagée n'appartient qu'à un des deux triangles (règle haut-gauche).
+func rasterTriangle(bounds image.Rectangle, v [3]ebiten.Vertex, fn func(x, y int, l [3]float64)) {
+	sign := orientation(v)
+	if sign == 0 {
+		return
+	}
+	if sign < 0 {
+		v[1], v[2] = v[2], v[1]
+	}
+
+	var p [3][2]float64
+	for k := range v {
+		p[k] = [2]float64{float64(v[k].DstX), float64(v[k].DstY)}
+	}
+	area := (p[1][0]-p[0][0])*(p[2][1]-p[0][1]) - (p[1][1]-p[0][1])*(p[2][0]-p[0][0])
+
+	minX := max(bounds.Min.X, int(math.Floor(min(p[0][0], p[1][0], p[2][0]))))
+	maxX := min(bounds.Max.X, int(math.Ceil(max(p[0][0], p[1][0], p[2][0]))))
+	minY := max(bounds.Min.Y, int(math.Floor(min(p[0][1], p[1][1], p[2][1]))))
+	maxY := min(bounds.Max.Y, int(math.Ceil(max(p[0][1], p[1][1], p[2][1]))))
+
+	for y := minY; y < maxY; y++ {
+		for x := minX; x < maxX; x++ {
+			px, py := float64(x)+0.5, float64(y)+0.5
+
+			var l [3]float64
+			inside := true
+			for k := 0; k < 3 && inside; k++ {
+				// Arête opposée au sommet k, positive à l'intérieur
+				a, b := p[(k+1)%3], p[(k+2)%3]
+				dx, dy := b[0]-a[0], b[1]-a[1]
+				e := dx*(py-a[1]) - dy*(px-a[0])
+				topLeft := -dy > 0 || (dy == 0 && dx > 0)
+				inside = e > 0 || (e == 0 && topLeft)
+				l[k] = e / area
+			}
+			if inside {
+				fn(x, y, l)
+			}
+		}
+	}
+}
+
+// rasterImage est l'équivalent logiciel de DrawImage(src) avec geoM, en
+// filtre nearest : chaque pixel de dst prend le pixel de src sous son centre
+func rasterImage(dst, src *image.RGBA, geoM ebiten.GeoM) {
+	inv := geoM
+	if !inv.IsInvertible() {
+		return
+	}
+	inv.Invert()
+
+	// Zone de dst couverte par les coins de src transformés
+	sb := src.Bounds()
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, c := range [][2]int{{sb.Min.X, sb.Min.Y}, {sb.Max.X, sb.Min.Y}, {sb.Min.X, sb.Max.Y}, {sb.Max.X, sb.Max.Y}} {
+		x, y := geoM.Apply(float64(c[0]-sb.Min.X), float64(c[1]-sb.Min.Y))
+		minX, maxX = math.Min(minX, x), math.Max(maxX, x)
+		minY, maxY = math.Min(minY, y), math.Max(maxY, y)
+	}
+	r := image.Rect(int(math.Floor(minX)), int(math.Floor(minY)), int(math.Ceil(maxX)), int(math.Ceil(maxY))).Intersect(dst.Bounds())
+
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			u, v := inv.Apply(float64(x)+0.5, float64(y)+0.5)
+			sp := image.Pt(sb.Min.X+int(math.Floor(u)), sb.Min.Y+int(math.Floor(v)))
+			if !sp.In(sb) {
+				continue
+			}
+			c := src.RGBAAt(sp.X, sp.Y)
+			blendPixel(dst, x, y, [4]float64{float64(c.R) / 255, float64(c.G) / 255, float64(c.B) / 255, float64(c.A) / 255})
+		}
+	}
+}
+
+// blendPixel mélange la couleur prémultipliée c (composantes de 0 à 1) au
+// pixel (x, y) de dst en mode source-over
+func blendPixel(dst *image.RGBA, x, y int, c [4]float64) {
+	i := dst.PixOffset(x, y)
+	for k := range c {
+		d := float64(dst.Pix[i+k]) / 255
+		dst.Pix[i+k] = uint8(math.Round(255 * math.Min(1, c[k]+d*(1-c[3]))))
+	}
+}